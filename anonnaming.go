@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-clang/clang-v13/clang"
+)
+
+// anonNameUnsafeRe matches characters that can't appear in a Nature
+// identifier, so a file basename like "sdl-video.h" becomes a usable
+// name fragment ("sdl_video_h").
+var anonNameUnsafeRe = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// anonLocationName derives a stable name for a truly anonymous struct or
+// union (one with no enclosing typedef to name it after) from its
+// declaration site - file basename, line, and column - instead of the
+// single shared "AnonymousStruct"/"AnonymousUnion" literal every such
+// aggregate used to collapse onto. Two distinct anonymous aggregates in
+// the same header get two distinct, order-independent names; re-running
+// naturebindgen over the same header always produces the same name for
+// the same declaration.
+func (bg *BindingGenerator) anonLocationName(cursor clang.Cursor, prefix string) string {
+	file, line, column, _ := cursor.Location().FileLocation()
+	if file == (clang.File{}) {
+		return prefix
+	}
+	base := filepath.Base(file.Name())
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	base = strings.Trim(anonNameUnsafeRe.ReplaceAllString(base, "_"), "_")
+	if base == "" {
+		return prefix
+	}
+	return fmt.Sprintf("%s_%s_%d_%d", prefix, base, line, column)
+}
+
+// structSignature returns a canonical string describing fields' names,
+// types, and bitfield layout, so two anonymous structs/unions declared
+// in different places but with identical shape (e.g. ten repeated
+// anonymous "{int x; int y;}" point-likes) can be recognized as the same
+// type instead of each getting its own emitted declaration.
+func structSignature(fields []StructField) string {
+	var sb strings.Builder
+	for _, f := range fields {
+		fmt.Fprintf(&sb, "%s:%s:%d:%d|", f.Name, f.Type, f.BitWidth, f.BitOffset)
+	}
+	return sb.String()
+}
+
+// dedupeAnonStruct looks up a previously registered anonymous struct
+// with the exact same structSignature as candidateName. If one exists,
+// it reports that name so the caller can reuse it instead of registering
+// a structurally-identical duplicate under a new name.
+func (bg *BindingGenerator) dedupeAnonStruct(candidateName string, fields []StructField) (string, bool) {
+	if !strings.Contains(candidateName, "AnonymousStruct_") && !strings.Contains(candidateName, "AnonymousUnion_") {
+		return "", false
+	}
+	sig := structSignature(fields)
+	if canonical, ok := bg.anonStructSignatures[sig]; ok {
+		return canonical, true
+	}
+	bg.anonStructSignatures[sig] = candidateName
+	return "", false
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteStructFieldsZeroWidthSeparatorStartsNewUnit(t *testing.T) {
+	// A zero-width separator (`int : 0;`) never reaches writeStructFields
+	// as a field of its own (see handleCursorStructDecl/
+	// registerClangJSONStruct, which drop it on parse rather than
+	// appending a bogus zero-width field); its only effect is that the
+	// bitfields after it restart BitOffset at 0 even though they share
+	// the same storage type as the run before it.
+	bg := NewBindingGenerator()
+	fields := []StructField{
+		{Name: "a", Type: "u32", BitWidth: 3, BitOffset: 0},
+		{Name: "b", Type: "u32", BitWidth: 4, BitOffset: 0}, // new unit after the separator
+	}
+
+	var sb strings.Builder
+	units := bg.writeStructFields(&sb, fields)
+
+	if len(units) != 2 {
+		t.Fatalf("got %d bitfield units, want 2 (separator forces a new unit)", len(units))
+	}
+	if units[0].Layout.Fields[0].Name != "a" || units[1].Layout.Fields[0].Name != "b" {
+		t.Fatalf("unexpected field grouping: %+v", units)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteStructFieldsGroupsMixedStorageUnits(t *testing.T) {
+	bg := NewBindingGenerator()
+	fields := []StructField{
+		{Name: "flags8", Type: "u8", BitWidth: 3, BitOffset: 0},
+		{Name: "rest8", Type: "u8", BitWidth: 5, BitOffset: 3},
+		{Name: "flags64", Type: "u64", BitWidth: 40, BitOffset: 0},
+	}
+
+	var sb strings.Builder
+	units := bg.writeStructFields(&sb, fields)
+
+	if len(units) != 2 {
+		t.Fatalf("got %d bitfield units, want 2 (one u8 run, one u64 run)", len(units))
+	}
+	if got, want := units[0].Layout.StorageType, "u8"; got != want {
+		t.Errorf("units[0].Layout.StorageType = %q, want %q", got, want)
+	}
+	if len(units[0].Layout.Fields) != 2 {
+		t.Errorf("units[0].Layout.Fields has %d fields, want 2", len(units[0].Layout.Fields))
+	}
+	if got, want := units[1].Layout.StorageType, "u64"; got != want {
+		t.Errorf("units[1].Layout.StorageType = %q, want %q", got, want)
+	}
+	if len(units[1].Layout.Fields) != 1 {
+		t.Errorf("units[1].Layout.Fields has %d fields, want 1", len(units[1].Layout.Fields))
+	}
+
+	out := sb.String()
+	if !strings.Contains(out, "u8 _bits0") {
+		t.Errorf("output missing u8 backing field:\n%s", out)
+	}
+	if !strings.Contains(out, "u64 _bits1") {
+		t.Errorf("output missing u64 backing field:\n%s", out)
+	}
+}
+
+func TestWriteBitfieldAccessorsUnsignedExtraction(t *testing.T) {
+	bg := NewBindingGenerator()
+	units := []bitfieldUnit{{
+		BackingField: "_bits0",
+		Layout: BitfieldLayout{
+			StorageType: "u32",
+			Fields:      []StructField{{Name: "flags", Type: "u32", BitWidth: 3, BitOffset: 2}},
+		},
+	}}
+
+	var sb strings.Builder
+	bg.writeBitfieldAccessors(&sb, "Foo", units)
+	out := sb.String()
+
+	if !strings.Contains(out, "fn Foo.get_flags():u32 {") {
+		t.Fatalf("missing unsigned getter signature:\n%s", out)
+	}
+	if !strings.Contains(out, "(self._bits0 >> 2) & 7") {
+		t.Fatalf("unsigned getter should mask+shift without sign-extension:\n%s", out)
+	}
+	if strings.Contains(out, "^") {
+		t.Fatalf("unsigned getter must not sign-extend:\n%s", out)
+	}
+}
+
+func TestWriteBitfieldAccessorsSignedExtraction(t *testing.T) {
+	bg := NewBindingGenerator()
+	units := []bitfieldUnit{{
+		BackingField: "_bits0",
+		Layout: BitfieldLayout{
+			StorageType: "i32",
+			Fields:      []StructField{{Name: "delta", Type: "i32", BitWidth: 4, BitOffset: 0}},
+		},
+	}}
+
+	var sb strings.Builder
+	bg.writeBitfieldAccessors(&sb, "Foo", units)
+	out := sb.String()
+
+	if !strings.Contains(out, "fn Foo.get_delta():i32 {") {
+		t.Fatalf("missing signed getter signature:\n%s", out)
+	}
+	// BitWidth 4 -> mask 15, sign bit 8, per the "(masked ^ signBit) - signBit" trick.
+	if !strings.Contains(out, "((self._bits0 >> 0) & 15) ^ 8) - 8") {
+		t.Fatalf("signed getter must sign-extend via the mask/xor/subtract trick:\n%s", out)
+	}
+	if !strings.Contains(out, "fn Foo.set_delta(value i32) {") {
+		t.Fatalf("missing setter signature:\n%s", out)
+	}
+}
+
+func TestNatureIntTypeBits(t *testing.T) {
+	cases := map[string]int{
+		"u8": 8, "i8": 8,
+		"u16": 16, "i16": 16,
+		"u32": 32, "i32": 32,
+		"u64": 64, "i64": 64,
+		"anyptr": 32, // default for anything unrecognized
+	}
+	for natureType, want := range cases {
+		if got := natureIntTypeBits(natureType); got != want {
+			t.Errorf("natureIntTypeBits(%q) = %d, want %d", natureType, got, want)
+		}
+	}
+}
+
+func TestIsSignedNatureIntType(t *testing.T) {
+	for _, signed := range []string{"i8", "i16", "i32", "i64"} {
+		if !isSignedNatureIntType(signed) {
+			t.Errorf("isSignedNatureIntType(%q) = false, want true", signed)
+		}
+	}
+	for _, unsigned := range []string{"u8", "u16", "u32", "u64", "anyptr"} {
+		if isSignedNatureIntType(unsigned) {
+			t.Errorf("isSignedNatureIntType(%q) = true, want false", unsigned)
+		}
+	}
+}
@@ -0,0 +1,476 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// cevalLookup resolves an identifier to its already-known constant value,
+// e.g. a previous #define or an earlier enum member.
+type cevalLookup func(name string) (int64, bool)
+
+// cevalSizeofLookup resolves a type name spelling (e.g. "int", "Uint32
+// *") inside a sizeof(...) expression to its size in bytes.
+type cevalSizeofLookup func(typeName string) (int64, bool)
+
+// cevalPrimitiveTypeSizes gives sizeof() for the built-in C types that
+// show up in header constant expressions. It deliberately doesn't cover
+// struct/union/typedef names - bg doesn't track byte sizes for those -
+// so sizeof(SomeStruct) still fails to fold, same as before this table
+// existed.
+var cevalPrimitiveTypeSizes = map[string]int64{
+	"char": 1, "signed char": 1, "unsigned char": 1,
+	"short": 2, "short int": 2, "unsigned short": 2, "unsigned short int": 2,
+	"int": 4, "unsigned int": 4, "unsigned": 4, "signed int": 4,
+	"long": 8, "long int": 8, "unsigned long": 8, "unsigned long int": 8,
+	"long long": 8, "long long int": 8, "unsigned long long": 8, "unsigned long long int": 8,
+	"float": 4, "double": 8, "long double": 16,
+	"void *": 8,
+}
+
+// defaultSizeofLookup is cevalPrimitiveTypeSizes as a cevalSizeofLookup,
+// also treating any "T *" spelling as a pointer (8 bytes on a 64-bit
+// target) regardless of what T is.
+func defaultSizeofLookup(typeName string) (int64, bool) {
+	typeName = strings.Join(strings.Fields(typeName), " ")
+	if strings.HasSuffix(typeName, "*") {
+		return 8, true
+	}
+	size, ok := cevalPrimitiveTypeSizes[typeName]
+	return size, ok
+}
+
+// evalConstExpr evaluates the subset of C integer constant expressions
+// that actually show up in headers: literals (with 0x/0b/0 prefixes and
+// u/l/ll suffixes), unary + - ~ !, the usual binary operators, ?:,
+// parenthesization, C-style casts "(T)e", sizeof(type) against
+// defaultSizeofLookup, and identifier references resolved via lookup. It
+// returns an error instead of silently producing 0 when the expression
+// can't be parsed or an identifier is unknown, so callers can fall back
+// to treating the value as an opaque string.
+func evalConstExpr(expr string, lookup cevalLookup) (int64, error) {
+	return evalConstExprSizeof(expr, lookup, defaultSizeofLookup)
+}
+
+// evalConstExprSizeof is evalConstExpr with an explicit sizeof(type)
+// resolver, for callers that want to plug in a richer type-size table
+// (e.g. one that also knows parsed struct sizes) than
+// defaultSizeofLookup.
+func evalConstExprSizeof(expr string, lookup cevalLookup, sizeofLookup cevalSizeofLookup) (int64, error) {
+	p := &cevalParser{tokens: cevalTokenize(expr), lookup: lookup, sizeofLookup: sizeofLookup}
+	value, err := p.parseTernary()
+	if err != nil {
+		return 0, err
+	}
+	if p.pos != len(p.tokens) {
+		return 0, fmt.Errorf("ceval: unexpected trailing token %q", p.tokens[p.pos])
+	}
+	return value, nil
+}
+
+// cevalTokenize splits expr into operator/punctuation/identifier/number
+// tokens, skipping whitespace.
+func cevalTokenize(expr string) []string {
+	var tokens []string
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case strings.ContainsRune("()?:~", c):
+			tokens = append(tokens, string(c))
+			i++
+		case strings.ContainsRune("+-*/%<>&|^=!", c):
+			// Greedily match two-character operators (<<, >>, &&, ||, ==, !=, <=, >=).
+			if i+1 < len(runes) && strings.ContainsRune("<>&|=", runes[i+1]) &&
+				(c == runes[i+1] || c == '<' || c == '>' || c == '!' || c == '=') {
+				tokens = append(tokens, string(runes[i:i+2]))
+				i += 2
+			} else {
+				tokens = append(tokens, string(c))
+				i++
+			}
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(runes) && (isHexDigit(runes[j]) || runes[j] == 'x' || runes[j] == 'X' ||
+				runes[j] == 'b' || runes[j] == 'B' || runes[j] == 'u' || runes[j] == 'U' || runes[j] == 'l' || runes[j] == 'L') {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		case isIdentStart(c):
+			j := i
+			for j < len(runes) && isIdentPart(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		default:
+			i++ // Skip any other character (',', '[', '.', quotes, etc.)
+		}
+	}
+	return tokens
+}
+
+func isHexDigit(r rune) bool {
+	return (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || (r >= '0' && r <= '9')
+}
+
+type cevalParser struct {
+	tokens       []string
+	pos          int
+	lookup       cevalLookup
+	sizeofLookup cevalSizeofLookup
+}
+
+func (p *cevalParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *cevalParser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+func (p *cevalParser) parseTernary() (int64, error) {
+	cond, err := p.parseBinary(0)
+	if err != nil {
+		return 0, err
+	}
+	if p.peek() != "?" {
+		return cond, nil
+	}
+	p.next()
+	whenTrue, err := p.parseTernary()
+	if err != nil {
+		return 0, err
+	}
+	if p.next() != ":" {
+		return 0, fmt.Errorf("ceval: expected ':' in ternary expression")
+	}
+	whenFalse, err := p.parseTernary()
+	if err != nil {
+		return 0, err
+	}
+	if cond != 0 {
+		return whenTrue, nil
+	}
+	return whenFalse, nil
+}
+
+// cevalPrecedence lists binary operators from lowest to highest
+// precedence, matching C's grouping (||, &&, |, ^, &, ==/!=, relational,
+// shift, additive, multiplicative).
+var cevalPrecedence = [][]string{
+	{"||"}, {"&&"}, {"|"}, {"^"}, {"&"},
+	{"==", "!="}, {"<", "<=", ">", ">="},
+	{"<<", ">>"}, {"+", "-"}, {"*", "/", "%"},
+}
+
+func (p *cevalParser) parseBinary(level int) (int64, error) {
+	if level == len(cevalPrecedence) {
+		return p.parseUnary()
+	}
+
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return 0, err
+	}
+	for contains(cevalPrecedence[level], p.peek()) {
+		op := p.next()
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return 0, err
+		}
+		left, err = applyBinaryOp(op, left, right)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return left, nil
+}
+
+func contains(set []string, v string) bool {
+	for _, s := range set {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func applyBinaryOp(op string, a, b int64) (int64, error) {
+	switch op {
+	case "||":
+		return boolToInt64(a != 0 || b != 0), nil
+	case "&&":
+		return boolToInt64(a != 0 && b != 0), nil
+	case "|":
+		return a | b, nil
+	case "^":
+		return a ^ b, nil
+	case "&":
+		return a & b, nil
+	case "==":
+		return boolToInt64(a == b), nil
+	case "!=":
+		return boolToInt64(a != b), nil
+	case "<":
+		return boolToInt64(a < b), nil
+	case "<=":
+		return boolToInt64(a <= b), nil
+	case ">":
+		return boolToInt64(a > b), nil
+	case ">=":
+		return boolToInt64(a >= b), nil
+	case "<<":
+		return a << uint(b), nil
+	case ">>":
+		return a >> uint(b), nil
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return 0, fmt.Errorf("ceval: division by zero")
+		}
+		return a / b, nil
+	case "%":
+		if b == 0 {
+			return 0, fmt.Errorf("ceval: division by zero")
+		}
+		return a % b, nil
+	default:
+		return 0, fmt.Errorf("ceval: unknown operator %q", op)
+	}
+}
+
+func boolToInt64(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (p *cevalParser) parseUnary() (int64, error) {
+	switch p.peek() {
+	case "+":
+		p.next()
+		return p.parseUnary()
+	case "-":
+		p.next()
+		v, err := p.parseUnary()
+		return -v, err
+	case "~":
+		p.next()
+		v, err := p.parseUnary()
+		return ^v, err
+	case "!":
+		p.next()
+		v, err := p.parseUnary()
+		return boolToInt64(v == 0), err
+	default:
+		return p.parsePrimary()
+	}
+}
+
+func (p *cevalParser) parsePrimary() (int64, error) {
+	tok := p.peek()
+	switch {
+	case tok == "":
+		return 0, fmt.Errorf("ceval: unexpected end of expression")
+	case tok == "(":
+		p.next()
+		if value, ok, err := p.tryParseCast(); ok {
+			return value, err
+		}
+		v, err := p.parseTernary()
+		if err != nil {
+			return 0, err
+		}
+		if p.next() != ")" {
+			return 0, fmt.Errorf("ceval: expected ')'")
+		}
+		return v, nil
+	case tok[0] >= '0' && tok[0] <= '9':
+		p.next()
+		return parseCIntLiteral(tok)
+	case isIdentStart(rune(tok[0])):
+		p.next()
+		if tok == "sizeof" {
+			return p.parseSizeof()
+		}
+		if p.lookup != nil {
+			if v, ok := p.lookup(tok); ok {
+				return v, nil
+			}
+		}
+		return 0, fmt.Errorf("ceval: unresolved identifier %q", tok)
+	default:
+		return 0, fmt.Errorf("ceval: unexpected token %q", tok)
+	}
+}
+
+// tryParseCast attempts to consume a C-style cast "(T)" assuming the
+// opening "(" has already been consumed by the caller, leaving p
+// positioned right after the cast's operand on success. It reports
+// ok=false and leaves p's position unchanged if what follows doesn't
+// look like "typename-tokens ')' value-starting-token" - the same
+// juxtaposition test a C compiler uses to disambiguate a cast from a
+// parenthesized expression followed by an operator. A lone identifier
+// that p.lookup resolves to a value, as in "(FOO) - 1", is treated as a
+// parenthesized operand rather than a type name.
+func (p *cevalParser) tryParseCast() (value int64, ok bool, err error) {
+	save := p.pos
+	typeName := p.collectTypeName()
+	if typeName == "" || p.peek() != ")" {
+		p.pos = save
+		return 0, false, nil
+	}
+	if p.lookup != nil && !strings.ContainsAny(typeName, " *") {
+		if _, isValue := p.lookup(typeName); isValue {
+			p.pos = save
+			return 0, false, nil
+		}
+	}
+	p.next() // consume ")"
+	if !isUnaryStartToken(p.peek()) {
+		p.pos = save
+		return 0, false, nil
+	}
+	v, castErr := p.parseUnary()
+	return v, true, castErr
+}
+
+// collectTypeName consumes a run of identifier/"*" tokens (a type
+// spelling like "unsigned int" or "Uint32 *") and returns it
+// space-joined, or "" without consuming anything if the next token
+// isn't part of one.
+func (p *cevalParser) collectTypeName() string {
+	var words []string
+	for {
+		tok := p.peek()
+		if tok == "" || !(tok == "*" || isIdentStart(rune(tok[0]))) {
+			break
+		}
+		words = append(words, tok)
+		p.next()
+	}
+	return strings.Join(words, " ")
+}
+
+// isUnaryStartToken reports whether tok could begin a unary expression,
+// the test tryParseCast uses to tell "(T)e" (a cast) from "(e)" followed
+// by a binary operator or the end of the expression.
+func isUnaryStartToken(tok string) bool {
+	if tok == "" {
+		return false
+	}
+	if strings.ContainsRune("(+-~!", rune(tok[0])) {
+		return true
+	}
+	return (tok[0] >= '0' && tok[0] <= '9') || isIdentStart(rune(tok[0]))
+}
+
+// parseSizeof parses the "(type)" in a sizeof(type) expression,
+// assuming the "sizeof" token has already been consumed, and resolves it
+// via p.sizeofLookup.
+func (p *cevalParser) parseSizeof() (int64, error) {
+	if p.peek() != "(" {
+		return 0, fmt.Errorf("ceval: sizeof requires a parenthesized type")
+	}
+	save := p.pos
+	p.next() // consume "("
+	typeName := p.collectTypeName()
+	if typeName == "" || p.peek() != ")" || p.sizeofLookup == nil {
+		p.pos = save
+		return 0, fmt.Errorf("ceval: sizeof(...) requires a type known to the size lookup")
+	}
+	size, ok := p.sizeofLookup(typeName)
+	if !ok {
+		p.pos = save
+		return 0, fmt.Errorf("ceval: sizeof(%s): unknown type", typeName)
+	}
+	p.next() // consume ")"
+	return size, nil
+}
+
+// parseCIntLiteral parses a C integer literal (0x1F, 0b101, 012, 1UL,
+// ...), stripping any u/U/l/L suffix before handing off to strconv.
+func parseCIntLiteral(tok string) (int64, error) {
+	end := len(tok)
+	for end > 0 && strings.ContainsRune("uUlL", rune(tok[end-1])) {
+		end--
+	}
+	digits := tok[:end]
+
+	switch {
+	case strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X"):
+		v, err := strconv.ParseInt(digits[2:], 16, 64)
+		return v, err
+	case strings.HasPrefix(digits, "0b") || strings.HasPrefix(digits, "0B"):
+		v, err := strconv.ParseInt(digits[2:], 2, 64)
+		return v, err
+	case len(digits) > 1 && digits[0] == '0':
+		v, err := strconv.ParseInt(digits, 8, 64)
+		return v, err
+	default:
+		v, err := strconv.ParseInt(digits, 10, 64)
+		return v, err
+	}
+}
+
+// inferConstantType infers a Nature integer type for the already-folded
+// value of expr, the same widening an evaluated C constant expression
+// gets from its literal suffixes: a u/U suffix on any literal makes the
+// whole expression unsigned, an l/L suffix widens it to 64 bits, and
+// failing either, a value that doesn't fit in a plain (32-bit) int also
+// widens - mirroring how an unsuffixed C literal like 1 << 40 still
+// ends up a 64-bit constant. Callers only reach this after evalConstExpr
+// has already folded expr to value; expr is re-scanned only for the
+// literal suffixes themselves; it's not re-evaluated.
+func inferConstantType(expr string, value int64) string {
+	hasUnsigned := false
+	hasLong := false
+	for _, tok := range cevalTokenize(expr) {
+		if tok == "" || tok[0] < '0' || tok[0] > '9' {
+			continue
+		}
+		suffix := strings.ToLower(strings.TrimLeft(tok, "0123456789xXbBoOabcdefABCDEF"))
+		if strings.Contains(suffix, "u") {
+			hasUnsigned = true
+		}
+		if strings.Contains(suffix, "l") {
+			hasLong = true
+		}
+	}
+
+	switch {
+	case hasUnsigned && (hasLong || value < 0 || value > math.MaxUint32):
+		return "u64"
+	case hasUnsigned:
+		return "u32"
+	case hasLong || value < math.MinInt32 || value > math.MaxInt32:
+		return "i64"
+	default:
+		return "i32"
+	}
+}
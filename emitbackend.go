@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// EmitBackend turns a fully parsed BindingGenerator into output text,
+// the step --emit selects between. It mirrors the Plugin registry in
+// plugins.go: a new target language registers itself under a name instead of
+// main()'s --emit handling growing another hardcoded case. The
+// substantial clang-parsing work in main.go (structs, unions, functions,
+// enums, constants all resolved to Nature-ish types) is reusable by any
+// EmitBackend, not just the default Nature emitter - EmitJSON's plain
+// AST dump is one example already in the tree.
+type EmitBackend interface {
+	Name() string
+	Emit(bg *BindingGenerator) (string, error)
+}
+
+// emitBackendRegistry holds every EmitBackend registered via
+// RegisterEmitBackend, keyed by Name(). Built-in backends register
+// themselves from init().
+var emitBackendRegistry = map[string]EmitBackend{}
+
+// RegisterEmitBackend makes b available to --emit by name. Re-registering
+// an existing name replaces it.
+func RegisterEmitBackend(b EmitBackend) {
+	emitBackendRegistry[b.Name()] = b
+}
+
+// lookupEmitBackend resolves a --emit name to a registered EmitBackend.
+func lookupEmitBackend(name string) (EmitBackend, error) {
+	b, ok := emitBackendRegistry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown --emit target %q", name)
+	}
+	return b, nil
+}
+
+// emitTargetFilename derives the output path for one backend out of
+// several requested via a comma-separated --emit (e.g. "nature,json"),
+// inserting the target name before outputFile's extension so
+// "bindings.n" becomes "bindings.nature.n" and "bindings.json.n".
+func emitTargetFilename(outputFile, target string) string {
+	ext := filepath.Ext(outputFile)
+	base := strings.TrimSuffix(outputFile, ext)
+	return fmt.Sprintf("%s.%s%s", base, target, ext)
+}
+
+func init() {
+	RegisterEmitBackend(natureEmitBackend{})
+	RegisterEmitBackend(jsonEmitBackend{})
+	RegisterEmitBackend(irEmitBackend{})
+}
+
+// natureEmitBackend is the default --emit=nature target: the full .n
+// bindings file generateNatureBindings has always produced.
+type natureEmitBackend struct{}
+
+func (natureEmitBackend) Name() string { return "nature" }
+
+func (natureEmitBackend) Emit(bg *BindingGenerator) (string, error) {
+	return bg.generateNatureBindings(), nil
+}
+
+// jsonEmitBackend is the --emit=json target: the schema-versioned IR
+// dump from EmitJSON, for downstream tooling that wants the parsed model
+// without the Nature-specific emitter.
+type jsonEmitBackend struct{}
+
+func (jsonEmitBackend) Name() string { return "json" }
+
+func (jsonEmitBackend) Emit(bg *BindingGenerator) (string, error) {
+	data, err := bg.EmitJSON()
+	return string(data), err
+}
+
+// irEmitBackend is --emit=ir, an alias for --emit=json kept for the name
+// a consumer thinking in "intermediate representation" terms would look
+// for; the output is identical.
+type irEmitBackend struct{}
+
+func (irEmitBackend) Name() string { return "ir" }
+
+func (irEmitBackend) Emit(bg *BindingGenerator) (string, error) {
+	return jsonEmitBackend{}.Emit(bg)
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/go-clang/clang-v13/clang"
+)
+
+// This file implements rust-bindgen-style scoping for large umbrella
+// headers such as <SDL.h>: --match restricts emission to decls whose
+// source file contains one of the given substrings,
+// --allowlist-*/--blocklist-* further restrict by symbol name, and
+// --link emits the linker directives a consumer would otherwise have to
+// hand-write into the generated file.
+
+// WithMatchPatterns restricts emitted declarations to cursors whose
+// source file contains at least one of patterns. Empty patterns (the
+// default) emits everything, matching prior behavior.
+func WithMatchPatterns(patterns []string) Option {
+	return func(bg *BindingGenerator) {
+		bg.matchPatterns = patterns
+	}
+}
+
+// WithLinkLibs records library names to emit as #linklib directives at
+// the top of the generated file, so callers don't hand-edit link lines
+// for every library the bindings depend on.
+func WithLinkLibs(libs []string) Option {
+	return func(bg *BindingGenerator) {
+		bg.linkLibs = libs
+	}
+}
+
+// WithFunctionFilter sets the allowlist/blocklist regexes applied to
+// function names in handleFunctionDecl. Either may be nil to skip that
+// check; the blocklist takes precedence over the allowlist.
+func WithFunctionFilter(allow, block *regexp.Regexp) Option {
+	return func(bg *BindingGenerator) {
+		bg.allowFunctionRe = allow
+		bg.blockFunctionRe = block
+	}
+}
+
+// WithTypeFilter sets the allowlist/blocklist regexes applied to struct,
+// union, and enum names in their respective handlers.
+func WithTypeFilter(allow, block *regexp.Regexp) Option {
+	return func(bg *BindingGenerator) {
+		bg.allowTypeRe = allow
+		bg.blockTypeRe = block
+	}
+}
+
+// locationAllowed reports whether cursor's source file passes --match
+// filtering, or true if no --match patterns were given. System headers
+// are already filtered out earlier in visitCursor; this narrows further,
+// e.g. to a single header among several #included ones.
+func (bg *BindingGenerator) locationAllowed(cursor clang.Cursor) bool {
+	if len(bg.matchPatterns) == 0 {
+		return true
+	}
+	file, _, _, _ := cursor.Location().FileLocation()
+	if file == (clang.File{}) {
+		return true
+	}
+	fileName := file.Name()
+	for _, pattern := range bg.matchPatterns {
+		if strings.Contains(fileName, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// functionAllowed applies --allowlist-function/--blocklist-function to
+// name, defaulting to true when neither was set.
+func (bg *BindingGenerator) functionAllowed(name string) bool {
+	return symbolAllowed(name, bg.allowFunctionRe, bg.blockFunctionRe)
+}
+
+// typeAllowed applies --allowlist-type/--blocklist-type to name,
+// defaulting to true when neither was set.
+func (bg *BindingGenerator) typeAllowed(name string) bool {
+	return symbolAllowed(name, bg.allowTypeRe, bg.blockTypeRe)
+}
+
+// compileFilterPattern compiles pattern as a regexp for flagName's CLI
+// value, returning a nil *regexp.Regexp (not an error) for an empty
+// pattern so callers can skip installing the filter entirely.
+func compileFilterPattern(flagName, pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid regexp %q: %w", flagName, pattern, err)
+	}
+	return re, nil
+}
+
+func symbolAllowed(name string, allow, block *regexp.Regexp) bool {
+	if block != nil && block.MatchString(name) {
+		return false
+	}
+	if allow != nil {
+		return allow.MatchString(name)
+	}
+	return true
+}
+
+// WithGenericFilter sets the --allow/--deny (and --allow-file/--deny-file)
+// regexes applied to every symbol kind - functions, structs, unions,
+// enums, typedefs, and macros alike - unlike WithFunctionFilter/
+// WithTypeFilter, which only cover their one kind each. Either may be nil
+// to skip that check; the deny list takes precedence.
+func WithGenericFilter(allow, deny *regexp.Regexp) Option {
+	return func(bg *BindingGenerator) {
+		bg.genericAllowRe = allow
+		bg.genericDenyRe = deny
+	}
+}
+
+// WithOnlyReachable sets --only-reachable: generateNatureBindings drops
+// any struct/union/typedef not transitively reachable from a retained
+// function's parameters or return type.
+func WithOnlyReachable(onlyReachable bool) Option {
+	return func(bg *BindingGenerator) {
+		bg.onlyReachable = onlyReachable
+	}
+}
+
+// genericSymbolAllowed applies --allow/--deny to name, defaulting to true
+// when neither was set. Declaration handlers call this alongside their
+// kind-specific functionAllowed/typeAllowed check, not instead of it.
+func (bg *BindingGenerator) genericSymbolAllowed(name string) bool {
+	return symbolAllowed(name, bg.genericAllowRe, bg.genericDenyRe)
+}
+
+// loadFilterPatternFile reads one regexp pattern per line from path for
+// --allow-file/--deny-file, skipping blank lines and "#"-prefixed
+// comments the same way a typical allow/deny list file would.
+func loadFilterPatternFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var patterns []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, nil
+}
+
+// compileFilterPatternList combines several patterns into a single
+// alternation regexp, matching any one of them, or nil if patterns is
+// empty.
+func compileFilterPatternList(flagName string, patterns []string) (*regexp.Regexp, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	combined := make([]string, len(patterns))
+	for i, p := range patterns {
+		combined[i] = "(?:" + p + ")"
+	}
+	return compileFilterPattern(flagName, strings.Join(combined, "|"))
+}
+
+// reachableTypes walks every retained (non-skipped) function's parameter
+// and return types, following struct fields, union fields, and typedef
+// aliases transitively, and returns the set of struct/union/typedef names
+// reached. --only-reachable uses this to drop declarations nothing
+// retained actually uses.
+func (bg *BindingGenerator) reachableTypes() map[string]bool {
+	reached := make(map[string]bool)
+	var visit func(typeName string)
+	visit = func(typeName string) {
+		typeName = baseTypeName(typeName)
+		if typeName == "" || reached[typeName] {
+			return
+		}
+		reached[typeName] = true
+		if structDef, ok := bg.structs[typeName]; ok {
+			for _, field := range structDef.Fields {
+				visit(field.Type)
+				for _, unionField := range field.UnionFields {
+					visit(unionField.Type)
+				}
+			}
+		}
+		if unionDef, ok := bg.unions[typeName]; ok {
+			for _, field := range unionDef.Fields {
+				visit(field.Type)
+			}
+		}
+		if mapping, ok := bg.typeMappings[typeName]; ok && mapping.NatureType != typeName {
+			visit(mapping.NatureType)
+		}
+	}
+
+	for name, fn := range bg.functions {
+		if bg.isSkipped(name) {
+			continue
+		}
+		visit(fn.ReturnType)
+		for _, param := range fn.Parameters {
+			visit(param.Type)
+		}
+	}
+	return reached
+}
+
+// baseTypeName strips the pointer/array/slice decoration mapCTypeToNature
+// and writeStructFields leave on a Nature type spelling (e.g. "*Foo",
+// "[]Foo", or the "rawptr<Foo>"/"ptr<Foo>" wrapper mapCTypeToNature emits
+// for C pointer types, see main.go's rawptr<%s> formatting), leaving the
+// bare type name reachableTypes looks up. Pointee types can themselves be
+// decorated (a typedef'd pointer-to-pointer), so unwrapping recurses.
+func baseTypeName(natureType string) string {
+	natureType = strings.TrimSpace(natureType)
+	if inner, ok := stripWrapper(natureType, "rawptr<"); ok {
+		return baseTypeName(inner)
+	}
+	if inner, ok := stripWrapper(natureType, "ptr<"); ok {
+		return baseTypeName(inner)
+	}
+	natureType = strings.TrimPrefix(natureType, "*")
+	natureType = strings.TrimPrefix(natureType, "[]")
+	return natureType
+}
+
+// stripWrapper reports whether natureType has the form prefix + inner +
+// ">" (e.g. "rawptr<Foo>"), returning the inner type if so.
+func stripWrapper(natureType, prefix string) (string, bool) {
+	if !strings.HasPrefix(natureType, prefix) || !strings.HasSuffix(natureType, ">") {
+		return "", false
+	}
+	return natureType[len(prefix) : len(natureType)-1], true
+}
+
+// writeLinkDirectives emits one #linklib directive per --link library,
+// mirroring the #linkid directives writeCallbackShims emits per symbol.
+func (bg *BindingGenerator) writeLinkDirectives(sb *strings.Builder) {
+	if len(bg.linkLibs) == 0 {
+		return
+	}
+	for _, lib := range bg.linkLibs {
+		sb.WriteString(fmt.Sprintf("#linklib %s\n", lib))
+	}
+	sb.WriteString("\n")
+}
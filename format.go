@@ -0,0 +1,38 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// This file implements --no-format: by default, every file naturebindgen
+// writes gets a final tidy-up pass - trimming trailing whitespace and
+// collapsing runs of blank lines the generator's section-by-section
+// assembly tends to accumulate (a section that emitted nothing still
+// leaves its blank-line separator behind) - before it's written. This
+// repo has no goimports/gofmt equivalent to reach for (the output is
+// Nature source, and this tool has no go.mod to add one as a dependency
+// of), so formatNatureSource is a small textual pass of our own instead.
+// --no-format skips it, e.g. to see the generator's raw, unprocessed
+// section boundaries while debugging a template.
+//
+// To be explicit about the substitution: this isn't a placeholder
+// standing in for a real goimports-equivalent pass we'd swap in later.
+// Nature has no import-reordering or unused-import-pruning concept for
+// such a tool to manage in the first place (see writeFunctionsSection
+// and friends - generated files are flat, dependency-free sections), so
+// there is nothing left for formatNatureSource to do beyond the
+// whitespace/blank-line cleanup above.
+
+var trailingWhitespaceRe = regexp.MustCompile(`[ \t]+\n`)
+var blankLineRunRe = regexp.MustCompile(`\n{3,}`)
+
+// formatNatureSource trims trailing whitespace from every line, collapses
+// three or more consecutive newlines down to two (a single blank line),
+// and ensures the result ends with exactly one trailing newline.
+func formatNatureSource(src string) string {
+	src = trailingWhitespaceRe.ReplaceAllString(src, "\n")
+	src = blankLineRunRe.ReplaceAllString(src, "\n\n")
+	src = strings.TrimRight(src, "\n") + "\n"
+	return src
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// This file implements the `naturebindgen from-ir <file.ir.json>`
+// subcommand: regenerate Nature bindings purely from a previously
+// written --emit=ir/json dump (see irDump/EmitJSON), skipping the C
+// parser entirely. Checking the IR into version control lets a
+// `//go:generate naturebindgen from-ir bindings.ir.json` re-entry point
+// reproduce the same bindings.n deterministically, and a hand-edited
+// copy of the IR (a renamed field, an overridden type mapping) can be
+// regenerated without re-running clang over the original header at all.
+
+// runFromIR implements the from-ir subcommand: args is os.Args[2:], i.e.
+// everything after "naturebindgen from-ir".
+func runFromIR(args []string) {
+	if len(args) < 1 {
+		fmt.Println("Usage: naturebindgen from-ir <file.ir.json> [-o output]")
+		os.Exit(1)
+	}
+
+	irFile := args[0]
+	outputFile := "bindings.n"
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case "-o", "--output":
+			if i+1 < len(args) {
+				outputFile = args[i+1]
+				i++
+			}
+		}
+	}
+
+	data, err := os.ReadFile(irFile)
+	if err != nil {
+		fmt.Printf("Error reading %s: %v\n", irFile, err)
+		os.Exit(1)
+	}
+
+	var dump irDump
+	if err := json.Unmarshal(data, &dump); err != nil {
+		fmt.Printf("Error parsing %s: %v\n", irFile, err)
+		os.Exit(1)
+	}
+	if dump.SchemaVersion != irDumpSchemaVersion {
+		fmt.Printf("Warning: %s has schema_version %d, naturebindgen expects %d; fields may not round-trip cleanly\n",
+			irFile, dump.SchemaVersion, irDumpSchemaVersion)
+	}
+
+	bg := NewBindingGenerator()
+	bg.restoreFromIR(dump)
+
+	if err := bg.WriteAll(outputFile); err != nil {
+		fmt.Printf("Error writing %s: %v\n", outputFile, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Generated bindings (from-ir): %s\n", outputFile)
+}
+
+// restoreFromIR loads dump's parsed model into bg, the inverse of
+// EmitJSON, registering every typedef with bg.env so
+// writeTypeDefsSection's LookupTypedef check recognizes it the same way
+// it would coming straight out of the C parser. This restores the full
+// model EmitJSON dumps - unions, func-pointer/callback typedefs,
+// variadic functions, and anonymous-union members included - so
+// `from-ir` reproduces the same bindings.n a normal parse of the
+// original header would, not just its functions/structs/enums.
+func (bg *BindingGenerator) restoreFromIR(dump irDump) {
+	if dump.Functions != nil {
+		bg.functions = dump.Functions
+	}
+	if dump.Structs != nil {
+		bg.structs = dump.Structs
+	}
+	if dump.Unions != nil {
+		bg.unions = dump.Unions
+	}
+	if dump.UnionNames != nil {
+		bg.unionNames = dump.UnionNames
+	}
+	if dump.Enums != nil {
+		bg.enums = dump.Enums
+	}
+	if dump.Constants != nil {
+		bg.constants = dump.Constants
+	}
+	if dump.FuncPtrTypes != nil {
+		bg.funcPtrTypes = dump.FuncPtrTypes
+	}
+	if dump.CallbackTypedefs != nil {
+		bg.callbackTypedefs = dump.CallbackTypedefs
+	}
+	if dump.VariadicFunctions != nil {
+		bg.variadicFunctions = dump.VariadicFunctions
+	}
+	if dump.AnonUnions != nil {
+		bg.anonUnions = dump.AnonUnions
+	}
+	for cType, mapping := range dump.Typedefs {
+		bg.typeMappings[cType] = mapping
+		bg.env.DefineTypedef(cType, mapping.NatureType)
+	}
+}
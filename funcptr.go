@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// funcPtrDeclRe matches a function-pointer declarator group — "(*)",
+// "(*name)", or "(CALLCONV *)" with an optional calling-convention macro
+// — immediately followed by the parameter list's opening paren.
+var funcPtrDeclRe = regexp.MustCompile(`\((?:[A-Za-z_]\w*\s+)?\*\s*(?:[A-Za-z_]\w*)?\s*\)\(`)
+
+// FuncPtrBinding is a C function-pointer type parsed well enough to
+// preserve its signature — return type and parameter types — instead of
+// collapsing to the generic "anyptr" that a bare `T (*)(...)` spelling
+// would otherwise get from mapCTypeToNature's pointer handling.
+type FuncPtrBinding struct {
+	Name       string
+	ReturnType string
+	Parameters []Parameter
+}
+
+// parseFuncPtrType parses a C function-pointer type spelling such as
+// "int (*)(void *, SDL_Event *)" or "int (SDLCALL *)(void *userdata,
+// SDL_Event *event)" into a FuncPtrBinding, handling nested parentheses
+// in both the declarator and the parameter list. It reports false if
+// cType isn't actually of that shape.
+func (bg *BindingGenerator) parseFuncPtrType(cType string) (FuncPtrBinding, bool) {
+	loc := funcPtrDeclRe.FindStringIndex(cType)
+	if loc == nil {
+		return FuncPtrBinding{}, false
+	}
+	declStart := loc[0]
+	paramsStart := loc[1] - 1 // the '(' that opens the parameter list
+
+	paramsEnd := matchingParen(cType, paramsStart)
+	if paramsEnd == -1 {
+		return FuncPtrBinding{}, false
+	}
+
+	returnTypeSpelling := strings.TrimSpace(cType[:declStart])
+	paramListSpelling := strings.TrimSpace(cType[paramsStart+1 : paramsEnd])
+
+	var parameters []Parameter
+	if paramListSpelling != "" && paramListSpelling != "void" {
+		for i, segment := range splitTopLevelCommas(paramListSpelling) {
+			paramType, paramName := splitParamDeclarator(strings.TrimSpace(segment))
+			if paramName == "" {
+				paramName = fmt.Sprintf("arg%d", i)
+			}
+			parameters = append(parameters, Parameter{
+				Name: bg.renameReservedKeywords(paramName),
+				Type: bg.mapCTypeToNature(paramType),
+			})
+		}
+	}
+
+	return FuncPtrBinding{
+		ReturnType: bg.mapCTypeToNature(returnTypeSpelling),
+		Parameters: parameters,
+	}, true
+}
+
+// natureSignature renders fp as a Nature function type, e.g.
+// "fn(anyptr, rawptr<SDL_Event>):i32".
+func (fp FuncPtrBinding) natureSignature() string {
+	paramTypes := make([]string, len(fp.Parameters))
+	for i, param := range fp.Parameters {
+		paramTypes[i] = param.Type
+	}
+	return fmt.Sprintf("fn(%s):%s", strings.Join(paramTypes, ", "), fp.ReturnType)
+}
+
+// matchingParen returns the index of the ')' that closes the '(' at
+// openIdx, accounting for nesting, or -1 if s[openIdx] isn't '(' or
+// it's unbalanced.
+func matchingParen(s string, openIdx int) int {
+	if openIdx < 0 || openIdx >= len(s) || s[openIdx] != '(' {
+		return -1
+	}
+	depth := 0
+	for i := openIdx; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevelCommas splits s on commas that aren't nested inside
+// parentheses, so a parameter that is itself a function pointer isn't
+// split on its own parameter-list commas.
+func splitTopLevelCommas(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, s[start:])
+	return parts
+}
+
+// splitParamDeclarator splits a single parameter declarator like
+// "void *userdata" into its type ("void *") and name ("userdata"). It
+// reports an empty name for unnamed parameters such as a bare "int" or a
+// nested function-pointer declarator.
+func splitParamDeclarator(decl string) (paramType, paramName string) {
+	if decl == "" {
+		return "", ""
+	}
+	// A function-pointer parameter (e.g. "void (*)(int)") has no trailing
+	// identifier to peel off; treat the whole thing as the type.
+	if strings.Contains(decl, "(*") {
+		return decl, ""
+	}
+
+	lastSpace := strings.LastIndexAny(decl, " *")
+	if lastSpace == -1 {
+		return decl, ""
+	}
+	candidate := decl[lastSpace+1:]
+	if candidate == "" || !isIdentifier(candidate) {
+		return decl, ""
+	}
+	// A trailing builtin type word ("unsigned int") or a tag/qualifier
+	// before the last word ("struct Foo", "const Foo") means the whole
+	// declarator is an unnamed parameter's type.
+	switch candidate {
+	case "void", "char", "short", "int", "long", "float", "double", "signed", "unsigned", "_Bool", "bool":
+		return decl, ""
+	}
+	switch strings.TrimSpace(decl[:lastSpace+1]) {
+	case "struct", "union", "enum", "const", "volatile", "const struct", "const union", "const enum":
+		return decl, ""
+	}
+	return strings.TrimSpace(decl[:lastSpace+1]), candidate
+}
+
+func isIdentifier(s string) bool {
+	for i, r := range s {
+		if i == 0 && !isIdentStart(r) {
+			return false
+		}
+		if i > 0 && !isIdentPart(r) {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+// This file implements the K&R-style parameter-list recovery both
+// registerClangJSONFunction and handleFunctionDecl fall back to when
+// libclang resolves a function as having an unspecified/no-prototype
+// parameter list. That case covers two distinct C forms:
+//
+//   - A true forward declaration, `int foo();`, which genuinely carries
+//     no parameter types anywhere in the translation unit - nothing to
+//     recover, so the caller's existing "assuming zero arguments"
+//     warning is the only honest option.
+//   - An old-style K&R *definition*, `int foo(a, b) int a; char *b; { ... }`,
+//     where the identifier list in the parens names the parameters and
+//     the declarations between `)` and `{` give their real types. libclang
+//     usually resolves this correctly on its own (the ParmVarDecl children
+//     already carry the declared types), but when a build is configured to
+//     parse only a declaration without its definition in scope (e.g. a
+//     prototype-only header paired with a .c file naturebindgen never
+//     sees), the caller only has the bare identifier list to go on.
+//
+// findKRParameters re-reads the raw header text naturebindgen already
+// parsed (bg.includedFiles) looking for the second form, so a forward
+// declaration whose definition appears later in the same file still
+// gets real parameter types instead of an empty list.
+var krHeaderRe = regexp.MustCompile(`(?s)\b([A-Za-z_]\w*)\s*\(\s*([A-Za-z_]\w*(?:\s*,\s*[A-Za-z_]\w*)*)\s*\)\s*((?:[^{};]*;\s*){0,16})\{`)
+
+// krDeclRe matches one K&R parameter declaration line, e.g. "char *b"
+// or "unsigned int x, y" (a single type shared by a comma list of
+// declarators, each optionally pointer-qualified).
+var krDeclRe = regexp.MustCompile(`^\s*(.+?)\s+(\*?\s*[A-Za-z_]\w*(?:\s*,\s*\*?\s*[A-Za-z_]\w*)*)\s*$`)
+
+// findKRParameters scans every file in sources for an old-style K&R
+// definition of funcName and, if one is found, returns the parameters it
+// declares - still in their original C type spelling, like
+// handleFunctionDecl's paramTypeSpelling, not yet passed through
+// mapCTypeToNature - in parameter-list order. ok is false if no K&R
+// definition of funcName was found, in which case the caller should fall
+// back to its existing warning.
+func (bg *BindingGenerator) findKRParameters(sources map[string]bool, funcName string) (params []Parameter, ok bool) {
+	for source := range sources {
+		content, err := os.ReadFile(source)
+		if err != nil {
+			continue
+		}
+		if params, ok = parseKRDefinition(string(content), funcName); ok {
+			return params, true
+		}
+	}
+	return nil, false
+}
+
+// parseKRDefinition looks for funcName's K&R-style definition in src -
+// `name(ident[, ident...]) [type decl;]... {` - and synthesizes a
+// []Parameter from the identifier list and the declarations that follow
+// it, in the original C type spelling (not yet mapped to Nature). An
+// identifier with no matching declaration line defaults to "int"
+// (K&R's implicit-int rule).
+func parseKRDefinition(src, funcName string) ([]Parameter, bool) {
+	for _, m := range krHeaderRe.FindAllStringSubmatch(src, -1) {
+		if m[1] != funcName {
+			continue
+		}
+		idents := splitIdentList(m[2])
+		types := parseKRDecls(m[3])
+
+		params := make([]Parameter, 0, len(idents))
+		for _, name := range idents {
+			cType, declared := types[name]
+			if !declared {
+				cType = "int"
+			}
+			params = append(params, Parameter{Name: name, Type: cType})
+		}
+		return params, true
+	}
+	return nil, false
+}
+
+// splitIdentList splits a K&R parameter list's bare identifier list
+// ("a, b, c") into its names.
+func splitIdentList(s string) []string {
+	var names []string
+	for _, part := range strings.Split(s, ",") {
+		if name := strings.TrimSpace(part); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// parseKRDecls parses the "type name;" declarations between a K&R
+// definition's parameter list and its opening brace (decls, as matched
+// by krHeaderRe's third group) into a name -> C type spelling map. A
+// single declaration can name more than one identifier of the same base
+// type ("int a, b;"); a "*" immediately before an identifier binds to
+// that identifier only, not its comma-siblings.
+func parseKRDecls(decls string) map[string]string {
+	types := make(map[string]string)
+	for _, line := range strings.Split(decls, ";") {
+		m := krDeclRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		baseType := strings.TrimSpace(stripKRStorageQualifiers(m[1]))
+		if baseType == "" {
+			continue
+		}
+		for _, declarator := range strings.Split(m[2], ",") {
+			declarator = strings.TrimSpace(declarator)
+			ptr := ""
+			if strings.HasPrefix(declarator, "*") {
+				ptr = "*"
+				declarator = strings.TrimSpace(strings.TrimPrefix(declarator, "*"))
+			}
+			if declarator == "" {
+				continue
+			}
+			cType := baseType
+			if ptr != "" {
+				cType = baseType + " " + ptr
+			}
+			types[declarator] = cType
+		}
+	}
+	return types
+}
+
+// krStorageQualifiers are pre-C99 declaration keywords a K&R parameter
+// declaration can legally carry ahead of its real type ("register int a;",
+// "const char *b;") that parseKRDecls's baseType must not treat as part
+// of the type itself.
+var krStorageQualifiers = []string{"register", "const", "volatile", "auto"}
+
+// stripKRStorageQualifiers removes any krStorageQualifiers word from
+// typeSpelling, so e.g. "register int" parses as base type "int".
+func stripKRStorageQualifiers(typeSpelling string) string {
+	words := strings.Fields(typeSpelling)
+	kept := words[:0]
+	for _, w := range words {
+		skip := false
+		for _, q := range krStorageQualifiers {
+			if w == q {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			kept = append(kept, w)
+		}
+	}
+	return strings.Join(kept, " ")
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -39,6 +42,17 @@ type StructBinding struct {
 	Fields []StructField
 }
 
+// fieldType returns the Nature type of sb's field named name, or ""
+// if there's no such field.
+func (sb StructBinding) fieldType(name string) string {
+	for _, field := range sb.Fields {
+		if field.Name == name {
+			return field.Type
+		}
+	}
+	return ""
+}
+
 // StructField represents a struct field
 type StructField struct {
 	Name        string
@@ -46,6 +60,15 @@ type StructField struct {
 	Nested      *StructBinding // For nested structs/unions
 	IsUnion     bool           // True if this field is a union
 	UnionFields []StructField  // If this is a union, these are the union fields
+	BitWidth    int            // Bitfield width in bits, 0 if not a bitfield
+	BitOffset   int            // Bit offset within its storage unit
+}
+
+// BitfieldLayout describes how a run of consecutive bitfields sharing one
+// storage unit is packed, for emitting mask/shift accessors.
+type BitfieldLayout struct {
+	StorageType string // Nature type of the backing storage unit, e.g. "u32"
+	Fields      []StructField
 }
 
 // EnumBinding represents a C enum binding
@@ -152,23 +175,260 @@ func (ub *UnionBinding) ToNature(bg *BindingGenerator) string {
 	return sb.String()
 }
 
+// anonUnionRef records one anonymous union member inlined directly into
+// its enclosing struct (C lets you write s.member for an anonymous
+// union member instead of s.anon.member), so its accessors can be
+// generated against the struct's own backing field instead of a
+// separate named union field.
+type anonUnionRef struct {
+	BackingField string
+	// UnionTypeName is the Nature type name the inlined union was
+	// registered under in bg.unions (see unionDeclTypeName) - its own C
+	// tag when named, a size-based synthetic name otherwise.
+	UnionTypeName string
+	UnionSize     int64
+	// Discriminant is the name of a preceding sibling field that looks
+	// like a tag for this union - named "type"/"kind"/"tag" (any case)
+	// and typed as a known enum - or "" if no such field was found.
+	// Only consulted when --union-mode=tagged asks for a variant()
+	// accessor built from it.
+	Discriminant string
+}
+
 // BindingGenerator generates Nature bindings from C headers
 type BindingGenerator struct {
-	typeMappings         map[string]TypeMapping
-	functions            map[string]FunctionBinding
-	structs              map[string]StructBinding
-	constants            map[string]ConstantItem
-	unions               map[int64]*UnionBinding
-	unionNames           map[string]int64 // Map union names to their sizes for type mapping
-	includes             []string
-	enums                map[string]EnumBinding
-	constantValues       map[string]int
-	includedFiles        map[string]bool
-	baseDir              string
-	headerLog            strings.Builder
-	nestedStructCounters map[string]int
-	processedCursors     map[clang.Cursor]bool // Track processed cursors to avoid duplicates
-	anonTypeNameMap      map[string]string     // Map clang spelling to context-based name
+	typeMappings          map[string]TypeMapping
+	functions             map[string]FunctionBinding
+	structs               map[string]StructBinding
+	constants             map[string]ConstantItem
+	unions                map[string]*UnionBinding // keyed by Nature type name (the C tag when named, a size-based synthetic name when anonymous), not byte size, so two differently-named unions of the same size stay distinct
+	unionNames            map[string]int64         // Map union names to their sizes for type mapping
+	includes              []string
+	enums                 map[string]EnumBinding
+	constantValues        map[string]int
+	includedFiles         map[string]bool
+	baseDir               string
+	headerLog             strings.Builder
+	nestedStructCounters  map[string]int
+	processedCursors      map[clang.Cursor]bool     // Track processed cursors to avoid duplicates
+	anonTypeNameMap       map[string]string         // Map clang spelling to context-based name
+	funcPtrTypes          map[string]FuncPtrBinding // Cache of parsed function-pointer type spellings
+	callbackTypedefs      map[string]FuncPtrBinding // typedef name -> signature, for named register_<Typedef>_cb/free_<Typedef>_cb shims
+	unhandledDeclKinds    map[string]int            // clang-CLI backend: counts of top-level decl kinds we didn't parse
+	unhandledDeclExamples map[string]string         // clang-CLI backend: first-seen representative name per unhandled kind
+	anonUnions            map[string][]anonUnionRef // struct name -> anonymous union members inlined into it
+	anonStructSignatures  map[string]string         // structSignature(fields) -> first-registered anonymous struct name, for dedupeAnonStruct
+
+	backend  ParserBackend
+	clangCmd string
+	cflags   []string
+
+	// taggedUnions selects --union-mode=tagged: anonymous unions with a
+	// detected discriminant sibling field also get a variant() accessor
+	// returning that field. Defaults to false (--union-mode=raw), which
+	// keeps the existing byte-array-plus-casts behavior unchanged.
+	taggedUnions bool
+
+	// splitOutput selects --split: WriteAll treats outputFile as a
+	// directory and writes one file per declaration category instead of
+	// a single monolithic file.
+	splitOutput bool
+
+	// noFormat selects --no-format: skips formatNatureSource's tidy-up
+	// pass, so written files keep the generator's raw section boundaries.
+	noFormat bool
+
+	// matchPatterns holds --match substrings; a decl is only emitted if
+	// its source file contains at least one of them (empty = no
+	// restriction). linkLibs holds --link library names, emitted as
+	// #linklib directives at the top of the generated file. The four
+	// regexes implement --allowlist-function/--blocklist-function and
+	// --allowlist-type/--blocklist-type; nil means "no restriction".
+	matchPatterns   []string
+	linkLibs        []string
+	allowFunctionRe *regexp.Regexp
+	blockFunctionRe *regexp.Regexp
+	allowTypeRe     *regexp.Regexp
+	blockTypeRe     *regexp.Regexp
+
+	// genericAllowRe/genericDenyRe implement --allow/--deny (and their
+	// --allow-file/--deny-file counterparts): unlike the *Function/*Type
+	// pairs above, these apply across every symbol kind a declaration
+	// handler registers - functions, structs, unions, enums, typedefs,
+	// and macros alike. onlyReachable selects --only-reachable: once set,
+	// generateNatureBindings drops any struct/union/typedef that isn't
+	// transitively referenced from a retained function signature.
+	genericAllowRe *regexp.Regexp
+	genericDenyRe  *regexp.Regexp
+	onlyReachable  bool
+
+	// variadicFunctions and variadicArity implement --variadic-arity: see
+	// VariadicFunction and writeVariadicWrappers in variadic.go.
+	variadicFunctions map[string]VariadicFunction
+	variadicArity     int
+
+	// noCache and cacheDir implement --no-cache/--cache-dir: see
+	// parseCacheEntry and loadParseCache/saveParseCache in parsecache.go.
+	noCache  bool
+	cacheDir string
+
+	// errorWrap implements --error-wrap: see ErrorWrapMode in plugins.go.
+	errorWrap ErrorWrapMode
+
+	env           *Env
+	overrides     *Overrides
+	serviceConfig *ServiceConfig
+}
+
+// ParserBackend selects how BindingGenerator turns a header into bindings.
+type ParserBackend int
+
+const (
+	// BackendCgo walks the AST in-process via go-clang/libclang (the
+	// default). It requires cgo and libclang development headers at
+	// build time.
+	BackendCgo ParserBackend = iota
+	// BackendClangCLI shells out to a clang binary and parses its
+	// `-ast-dump=json` output instead, trading some fidelity (no access
+	// to libclang's richer cursor API) for not needing cgo.
+	BackendClangCLI
+)
+
+// Option configures a BindingGenerator at construction time.
+type Option func(*BindingGenerator)
+
+// WithClangFrontend selects the BackendClangCLI parser, shelling out to
+// clangPath with cflags appended to the ast-dump invocation instead of
+// walking the AST in-process via go-clang.
+func WithClangFrontend(clangPath string, cflags []string) Option {
+	return func(bg *BindingGenerator) {
+		bg.backend = BackendClangCLI
+		bg.clangCmd = clangPath
+		bg.cflags = cflags
+	}
+}
+
+// WithTaggedUnions selects --union-mode=tagged.
+func WithTaggedUnions() Option {
+	return func(bg *BindingGenerator) {
+		bg.taggedUnions = true
+	}
+}
+
+// WithSplitOutput selects --split: see writeSplit.
+func WithSplitOutput() Option {
+	return func(bg *BindingGenerator) {
+		bg.splitOutput = true
+	}
+}
+
+// WithNoFormat selects --no-format: see formatNatureSource.
+func WithNoFormat() Option {
+	return func(bg *BindingGenerator) {
+		bg.noFormat = true
+	}
+}
+
+// WithNoCache selects --no-cache: parseHeaderFile always reparses, never
+// reading or writing a parseCacheEntry.
+func WithNoCache() Option {
+	return func(bg *BindingGenerator) {
+		bg.noCache = true
+	}
+}
+
+// WithCacheDir selects --cache-dir, overriding defaultCacheDir().
+func WithCacheDir(dir string) Option {
+	return func(bg *BindingGenerator) {
+		bg.cacheDir = dir
+	}
+}
+
+// WithErrorWrapping selects --error-wrap=<mode>, controlling how much
+// failure-handling detail docsPlugin's fallibleReturnNote surfaces for a
+// fallible function. See ErrorWrapMode for why this is documentation-only
+// rather than emitted call-wrapping code.
+func WithErrorWrapping(mode ErrorWrapMode) Option {
+	return func(bg *BindingGenerator) {
+		bg.errorWrap = mode
+	}
+}
+
+// Env implements C's separate tag and typedef/ordinary namespaces, plus
+// the set of known enum type names, as scoped lookup tables, pushed and popped as nested
+// declarations (e.g. a struct's fields) are visited. This replaces
+// linear scans over bg.structs/bg.enums in mapCTypeToNature with O(1)
+// lookups, and lets a typedef shadow a struct tag of the same name
+// instead of the two being conflated.
+type Env struct {
+	tags     []map[string]string // "struct Foo"/"union Foo" tag -> Nature type
+	typedefs []map[string]string // typedef/ordinary type name -> Nature type
+	enums    []map[string]bool   // known enum type names (resolve to "int")
+}
+
+// NewEnv returns an Env with a single, unpoppable file scope.
+func NewEnv() *Env {
+	return &Env{
+		tags:     []map[string]string{make(map[string]string)},
+		typedefs: []map[string]string{make(map[string]string)},
+		enums:    []map[string]bool{make(map[string]bool)},
+	}
+}
+
+// PushScope opens a new nested scope, e.g. on entering a struct body.
+func (e *Env) PushScope() {
+	e.tags = append(e.tags, make(map[string]string))
+	e.typedefs = append(e.typedefs, make(map[string]string))
+	e.enums = append(e.enums, make(map[string]bool))
+}
+
+// PopScope closes the innermost scope. The file scope is never popped.
+func (e *Env) PopScope() {
+	if len(e.tags) == 1 {
+		return
+	}
+	e.tags = e.tags[:len(e.tags)-1]
+	e.typedefs = e.typedefs[:len(e.typedefs)-1]
+	e.enums = e.enums[:len(e.enums)-1]
+}
+
+func (e *Env) DefineTag(name, natureType string) {
+	e.tags[len(e.tags)-1][name] = natureType
+}
+
+func (e *Env) DefineTypedef(name, natureType string) {
+	e.typedefs[len(e.typedefs)-1][name] = natureType
+}
+
+func (e *Env) DefineEnum(name string) {
+	e.enums[len(e.enums)-1][name] = true
+}
+
+func (e *Env) LookupTag(name string) (string, bool) {
+	for i := len(e.tags) - 1; i >= 0; i-- {
+		if t, ok := e.tags[i][name]; ok {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+func (e *Env) LookupTypedef(name string) (string, bool) {
+	for i := len(e.typedefs) - 1; i >= 0; i-- {
+		if t, ok := e.typedefs[i][name]; ok {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+func (e *Env) IsEnum(name string) bool {
+	for i := len(e.enums) - 1; i >= 0; i-- {
+		if e.enums[i][name] {
+			return true
+		}
+	}
+	return false
 }
 
 func areStringsEqualAfterDynamicPrefixTrim(s1, s2 string) bool {
@@ -225,22 +485,39 @@ func IsLiteral(kind clang.CursorKind) bool {
 	return int(kind) >= int(clang.Cursor_IntegerLiteral) && int(kind) <= int(clang.Cursor_StringLiteral) || kind == clang.Cursor_VarDecl
 }
 
-// NewBindingGenerator creates a new binding generator
-func NewBindingGenerator() *BindingGenerator {
+// NewBindingGenerator creates a new binding generator. By default it
+// parses headers in-process via go-clang (BackendCgo); pass
+// WithClangFrontend to shell out to a clang binary instead.
+func NewBindingGenerator(opts ...Option) *BindingGenerator {
 	bg := &BindingGenerator{
-		typeMappings:         make(map[string]TypeMapping),
-		functions:            make(map[string]FunctionBinding),
-		structs:              make(map[string]StructBinding),
-		constants:            make(map[string]ConstantItem),
-		unions:               make(map[int64]*UnionBinding),
-		unionNames:           make(map[string]int64),
-		includes:             make([]string, 0),
-		enums:                make(map[string]EnumBinding),
-		constantValues:       make(map[string]int),
-		includedFiles:        make(map[string]bool),
-		nestedStructCounters: make(map[string]int),
-		processedCursors:     make(map[clang.Cursor]bool),
-		anonTypeNameMap:      make(map[string]string),
+		typeMappings:          make(map[string]TypeMapping),
+		functions:             make(map[string]FunctionBinding),
+		structs:               make(map[string]StructBinding),
+		constants:             make(map[string]ConstantItem),
+		unions:                make(map[string]*UnionBinding),
+		variadicFunctions:     make(map[string]VariadicFunction),
+		variadicArity:         DefaultVariadicArity,
+		cacheDir:              defaultCacheDir(),
+		unionNames:            make(map[string]int64),
+		includes:              make([]string, 0),
+		enums:                 make(map[string]EnumBinding),
+		constantValues:        make(map[string]int),
+		includedFiles:         make(map[string]bool),
+		nestedStructCounters:  make(map[string]int),
+		processedCursors:      make(map[clang.Cursor]bool),
+		anonTypeNameMap:       make(map[string]string),
+		funcPtrTypes:          make(map[string]FuncPtrBinding),
+		callbackTypedefs:      make(map[string]FuncPtrBinding),
+		unhandledDeclKinds:    make(map[string]int),
+		unhandledDeclExamples: make(map[string]string),
+		anonUnions:            make(map[string][]anonUnionRef),
+		anonStructSignatures:  make(map[string]string),
+		backend:               BackendCgo,
+		env:                   NewEnv(),
+	}
+
+	for _, opt := range opts {
+		opt(bg)
 	}
 
 	// Initialize default type mappings based on Nature documentation
@@ -326,32 +603,61 @@ func (bg *BindingGenerator) mapCursorKindToCType(kind clang.CursorKind) string {
 }
 
 // mapCTypeToNature converts a C type to its Nature equivalent
+// cvQualifierRe matches a standalone "const" or "volatile" token, so it
+// can be stripped from a type spelling without touching identifiers that
+// merely contain those words as a substring (e.g. a hypothetical
+// "constants_t").
+var cvQualifierRe = regexp.MustCompile(`\b(const|volatile)\b\s*`)
+
+// stripCVQualifiers removes "const"/"volatile" qualifiers from a type
+// spelling. Nature has no const/volatile notion of its own, so without
+// this a qualified spelling like "const SDL_Window *" fails every lookup
+// mapCTypeToNature does against its unqualified form ("SDL_Window *")
+// and silently falls through to the generic anyptr/any case, even though
+// the unqualified spelling of the exact same type resolves correctly.
+func stripCVQualifiers(cType string) string {
+	return strings.TrimSpace(cvQualifierRe.ReplaceAllString(cType, ""))
+}
+
 func (bg *BindingGenerator) mapCTypeToNature(cType string) string {
 	// Clean up the type string
 	cType = strings.TrimSpace(cType)
 	cType = regexp.MustCompile(`\s+`).ReplaceAllString(cType, " ")
+	cType = stripCVQualifiers(cType)
+
+	if natureType, ok := bg.overrideType(cType); ok {
+		return natureType
+	}
 
-	// Handle function pointer types
-	if strings.Contains(cType, "(*") && strings.Contains(cType, ")(") {
+	// Handle function pointer types, preserving the real signature instead
+	// of collapsing to anyptr.
+	if funcPtrDeclRe.MatchString(cType) {
+		if cached, ok := bg.funcPtrTypes[cType]; ok {
+			return cached.natureSignature()
+		}
+		if fp, ok := bg.parseFuncPtrType(cType); ok {
+			bg.funcPtrTypes[cType] = fp
+			return fp.natureSignature()
+		}
 		return "anyptr"
 	}
 
 	// Handle pointer types
 	if strings.Contains(cType, "*") {
 		baseType := strings.TrimSpace(strings.Replace(cType, "*", "", -1))
+		baseTag := strings.TrimSpace(strings.TrimPrefix(baseType, "struct "))
 
-		// Check if it's a pointer to a known struct
-		for _, structDef := range bg.structs {
-			if baseType == structDef.Name || baseType == "struct "+structDef.Name {
-				return fmt.Sprintf("rawptr<%s>", structDef.Name)
-			}
+		// Check if it's a pointer to a known struct, via the tag namespace
+		if _, ok := bg.env.LookupTag(baseType); ok {
+			return fmt.Sprintf("rawptr<%s>", baseType)
+		}
+		if _, ok := bg.env.LookupTag(baseTag); ok {
+			return fmt.Sprintf("rawptr<%s>", baseTag)
 		}
 
 		// Check if it's a pointer to a known enum type
-		for _, enumDef := range bg.enums {
-			if baseType == enumDef.Name {
-				return "rawptr<int>"
-			}
+		if bg.env.IsEnum(baseType) {
+			return "rawptr<int>"
 		}
 
 		// Default pointer types
@@ -397,34 +703,45 @@ func (bg *BindingGenerator) mapCTypeToNature(cType string) string {
 		return mapping.NatureType
 	}
 
-	// Check if it's a known struct type
-	for _, structDef := range bg.structs {
-		if cType == structDef.Name {
-			return structDef.Name
-		}
+	// Check if it's a known struct type, via the tag namespace
+	if _, ok := bg.env.LookupTag(cType); ok {
+		return cType
 	}
 
-	// Check if it's a known union type
-	if unionSize, exists := bg.unionNames[cType]; exists {
-		// Return the union type name based on size
-		unionTypeName := fmt.Sprintf("Union_%s_bytes", num2words.Convert(int(unionSize)))
-		bg.headerLog.WriteString(fmt.Sprintf("[DEBUG] Mapped union type %s to %s\n", cType, unionTypeName))
-		return unionTypeName
+	// Check if it's a known union type - bg.unionNames is keyed by the
+	// same Nature type name the union is registered under (its own C tag
+	// when named, a size-based synthetic name otherwise), so cType is
+	// already the answer once it's a known key.
+	if _, exists := bg.unionNames[cType]; exists {
+		bg.headerLog.WriteString(fmt.Sprintf("[DEBUG] Mapped union type %s to %s\n", cType, cType))
+		return cType
 	}
 
-	// Check if it's a known enum type
-	for _, enumDef := range bg.enums {
-		if cType == enumDef.Name {
-			return "int"
-		}
+	// Check if it's a known enum type, via Env's known-enum table
+	if bg.env.IsEnum(cType) {
+		return "int"
 	}
 
 	// Default to any for truly unknown types
 	return "any"
 }
 
-// parseHeaderFile parses a C header file using go-clang
+// parseHeaderFile parses a C header file, dispatching to the configured
+// backend (go-clang in-process by default, or clang-CLI when
+// WithClangFrontend was used).
 func (bg *BindingGenerator) parseHeaderFile(filename string) error {
+	if bg.backend == BackendClangCLI {
+		return bg.parseHeaderFileViaClangCLI(filename)
+	}
+
+	if !bg.noCache {
+		if entry, ok := loadParseCache(bg.cacheDir, filename, bg.cflags); ok {
+			bg.restoreFromCache(entry)
+			bg.headerLog.WriteString(fmt.Sprintf("Parse cache hit for %s\n", filename))
+			return nil
+		}
+	}
+
 	// Mark this file as included
 	bg.includedFiles[filename] = true
 	bg.headerLog.WriteString(fmt.Sprintf("Parsing header: %s\n", filename))
@@ -459,141 +776,646 @@ func (bg *BindingGenerator) parseHeaderFile(filename string) error {
 	// Visit all children to find declarations
 	bg.visitCursor(cursor, 0)
 
+	if !bg.noCache {
+		if err := bg.saveParseCache(bg.cacheDir, filename, bg.cflags); err != nil {
+			fmt.Printf("Warning: could not write parse cache: %v\n", err)
+		}
+	}
+
 	return nil
 }
 
-// visitCursor recursively visits all cursors in the AST
-func (bg *BindingGenerator) visitCursor(cursor clang.Cursor, depth int) {
-	// Skip system headers
-	if cursor.Location().IsInSystemHeader() {
-		return
+// definePattern recognizes a preprocessed `#define NAME VALUE` line as
+// emitted by `-dD`, capturing simple object-like macros. Function-like
+// macros (`NAME(args) ...`) are intentionally left to the caller to skip,
+// since they have no single constant value.
+var definePattern = regexp.MustCompile(`^#define\s+([A-Za-z_][A-Za-z0-9_]*)\s+(.+)$`)
+
+// macroStubPattern recognizes one line of a user-supplied macro-stubs
+// file (the `--macros` flag): `#define NAME` with no replacement text
+// (a vendor annotation like SDL_PRINTF_FORMAT_STRING that should just
+// disappear) or `#define NAME VALUE`.
+var macroStubPattern = regexp.MustCompile(`^#define\s+([A-Za-z_][A-Za-z0-9_]*)(?:\s+(.+))?$`)
+
+// loadMacroStubs reads a macro-stubs file (one `#define NAME` or
+// `#define NAME VALUE` per line, blank lines and lines starting with #
+// outside of a #define ignored) and returns it as clang `-D` flags, so
+// unknown vendor macros like SDL_PRINTF_FORMAT_STRING or
+// __attribute__-wrapping annotations can be defined away before the
+// clang-CLI backend's AST dump ever sees them.
+func loadMacroStubs(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading macros file: %w", err)
 	}
 
-	// Check if cursor has already been processed
-	if _, exists := bg.processedCursors[cursor]; exists {
-		return
+	var flags []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || !strings.HasPrefix(line, "#define") {
+			continue
+		}
+		matches := macroStubPattern.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		name, value := matches[1], strings.TrimSpace(matches[2])
+		if value == "" {
+			flags = append(flags, "-D"+name)
+		} else {
+			flags = append(flags, fmt.Sprintf("-D%s=%s", name, value))
+		}
 	}
-	bg.processedCursors[cursor] = true
+	return flags, nil
+}
 
-	kind := cursor.Kind()
-	spelling := cursor.Spelling()
+// PreprocessWith runs the C preprocessor (cmd, e.g. "cc", with args such
+// as ["-E", "-dD"]) over filename so conditional compilation and
+// `#include` are resolved the same way a real C compiler would see them,
+// then feeds the expanded translation unit into the configured parser.
+// It also records every object-like `#define` found in the `-dD` output
+// as a constant, which catches macros the AST walk itself never sees
+// because they were already expanded away by the preprocessor.
+func (bg *BindingGenerator) PreprocessWith(filename, cmd string, args []string, defines map[string]string, includeDirs []string) error {
+	cmdArgs := append([]string{}, args...)
+	for name, value := range defines {
+		if value == "" {
+			cmdArgs = append(cmdArgs, "-D"+name)
+		} else {
+			cmdArgs = append(cmdArgs, fmt.Sprintf("-D%s=%s", name, value))
+		}
+	}
+	for _, dir := range includeDirs {
+		cmdArgs = append(cmdArgs, "-I"+dir)
+	}
 
-	bg.headerLog.WriteString(fmt.Sprintf("%s[DEBUG] Visiting cursor: %s (%s) at depth %d\n",
-		strings.Repeat("  ", depth), spelling, kind.String(), depth))
+	tmpFile := filepath.Join(os.TempDir(), filepath.Base(filename)+".i")
 
-	switch kind {
-	case clang.Cursor_StructDecl:
-		// For anonymous structs, we need to find a proper context
-		if spelling == "" || strings.Contains(spelling, "unnamed") || strings.Contains(spelling, " at ") {
-			// This is an anonymous struct, we need to find its parent context
-			parent := cursor.SemanticParent()
-			if parent.Kind() == clang.Cursor_TypedefDecl {
-				// This is a typedef struct, use the typedef name as context
-				typedefName := parent.Spelling()
-				bg.handleCursorStructDecl(cursor, typedefName, depth)
-			} else {
-				// For truly anonymous structs, use a generic context
-				bg.handleCursorStructDecl(cursor, "AnonymousStruct", depth)
-			}
-		} else {
-			bg.handleCursorStructDecl(cursor, spelling, depth)
+	cmdArgs = append(cmdArgs, filename, "-o", tmpFile)
+	out, err := exec.Command(cmd, cmdArgs...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("preprocessing failed: %w: %s", err, out)
+	}
+	defer os.Remove(tmpFile)
+
+	expanded, err := os.ReadFile(tmpFile)
+	if err != nil {
+		return fmt.Errorf("failed to read preprocessed output: %w", err)
+	}
+
+	for _, line := range strings.Split(string(expanded), "\n") {
+		matches := definePattern.FindStringSubmatch(line)
+		if matches == nil {
+			continue
 		}
-	case clang.Cursor_FieldDecl:
-		bg.handleFieldDecl(cursor, nil, depth)
-	case clang.Cursor_TypedefDecl:
-		bg.handleTypedefDecl(cursor, depth)
-	case clang.Cursor_FunctionDecl:
-		bg.handleFunctionDecl(cursor, depth)
-	case clang.Cursor_EnumDecl:
-		bg.handleEnumDecl(cursor, depth)
-	case clang.Cursor_UnionDecl:
-		// For anonymous unions, we need to find a proper context
-		if spelling == "" || strings.Contains(spelling, "unnamed") || strings.Contains(spelling, " at ") {
-			// This is an anonymous union, we need to find its parent context
-			parent := cursor.SemanticParent()
-			if parent.Kind() == clang.Cursor_TypedefDecl {
-				// This is a typedef union, use the typedef name as context
-				typedefName := parent.Spelling()
-				bg.handleCursorUnionDecl(cursor, typedefName, depth)
-			} else {
-				// For truly anonymous unions, use a generic context
-				bg.handleCursorUnionDecl(cursor, "AnonymousUnion", depth)
-			}
+		name, value := matches[1], strings.TrimSpace(matches[2])
+		if strings.Contains(matches[0], name+"(") {
+			continue // Function-like macro; no single constant value
+		}
+
+		bg.constants[name] = ConstantItem{Name: name, Type: bg.inferMacroValueType(value), Value: value}
+		if intValue, err := strconv.Atoi(value); err == nil {
+			bg.constantValues[name] = intValue
+		} else if evaluated, err := evalConstExpr(value, bg.lookupConstantValue); err == nil {
+			bg.constantValues[name] = int(evaluated)
 		} else {
-			bg.handleCursorUnionDecl(cursor, spelling, depth)
+			fmt.Printf("Warning: could not evaluate macro %s = %q: %v\n", name, value, err)
 		}
-	case clang.Cursor_MacroDefinition: // Only call handleMacroDefinition for actual macros
-		literalType := cursor.Type() // Although for MacroDefinition, this might not be strictly a "literal type" but the underlying type of the macro's expansion if it's a constant.
-		bg.handleMacroDefinition(cursor, depth, literalType)
-	case clang.Cursor_InclusionDirective:
-		bg.handleIncludeDirective(cursor, depth)
+	}
+
+	return bg.parseHeaderFile(tmpFile)
+}
+
+// lookupConstantValue resolves name against already-evaluated macros and
+// enum members, for use as a ceval identifier environment.
+func (bg *BindingGenerator) lookupConstantValue(name string) (int64, bool) {
+	if v, ok := bg.constantValues[name]; ok {
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// inferMacroValueType guesses a Nature type for a preprocessed macro
+// value using the same heuristic as handleMacroDefinition.
+func (bg *BindingGenerator) inferMacroValueType(value string) string {
+	if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
+		return "string"
+	}
+	if strings.Contains(value, ".") {
+		return "f64"
+	}
+	if evaluated, err := evalConstExpr(value, bg.lookupConstantValue); err == nil {
+		return inferConstantType(value, evaluated)
+	}
+	return "i32"
+}
+
+// parseHeaderFileViaClangCLI parses a header by shelling out to
+// bg.clangCmd with `-Xclang -ast-dump=json -fsyntax-only` and bg.cflags,
+// then walking the resulting JSON AST. It covers the subset of
+// declarations (structs, enums, plain functions) needed for typical
+// bindings; anonymous aggregates and function pointers are handled more
+// thoroughly by the default BackendCgo path.
+func (bg *BindingGenerator) parseHeaderFileViaClangCLI(filename string) error {
+	bg.includedFiles[filename] = true
+	bg.headerLog.WriteString(fmt.Sprintf("Parsing header via clang CLI: %s\n", filename))
+
+	clangCmd := bg.clangCmd
+	if clangCmd == "" {
+		clangCmd = "clang"
+	}
+
+	args := append([]string{"-Xclang", "-ast-dump=json", "-fsyntax-only"}, bg.cflags...)
+	args = append(args, filename)
+
+	out, err := exec.Command(clangCmd, args...).Output()
+	if err != nil {
+		return fmt.Errorf("clang -ast-dump=json failed: %w", err)
+	}
+
+	var root map[string]any
+	if err := json.Unmarshal(out, &root); err != nil {
+		return fmt.Errorf("failed to parse clang ast-dump json: %w", err)
+	}
+
+	bg.walkClangASTNode(root, filename)
+	bg.PrintUnhandledDeclReport()
+	return nil
+}
+
+// walkClangASTNode recursively walks a clang -ast-dump=json node,
+// registering the declarations bg cares about and recursing into
+// "inner" children.
+func (bg *BindingGenerator) walkClangASTNode(node map[string]any, filename string) {
+	kind, _ := node["kind"].(string)
+
+	handled := true
+	switch kind {
+	case "RecordDecl":
+		bg.registerClangJSONStruct(node)
+	case "EnumDecl":
+		bg.registerClangJSONEnum(node)
+	case "FunctionDecl":
+		bg.registerClangJSONFunction(node)
+	case "TypedefDecl":
+		bg.registerClangJSONTypedef(node)
 	default:
-		bg.headerLog.WriteString(fmt.Sprintf("%s[DEBUG] Unknown cursor kind: %s, %d\n", strings.Repeat("  ", depth), kind.String(), int(kind)))
-		initalizerCursor := cursor.VarDeclInitializer()
+		handled = false
+	}
+	if !handled {
+		bg.recordUnhandledDeclKind(kind, node)
+	}
 
-		if !initalizerCursor.IsNull() {
-			kind = initalizerCursor.Kind()
+	inner, _ := node["inner"].([]any)
+	for _, child := range inner {
+		if childNode, ok := child.(map[string]any); ok {
+			bg.walkClangASTNode(childNode, filename)
 		}
 	}
+}
 
-	// Visit children
-	cursor.Visit(func(cursor, parent clang.Cursor) clang.ChildVisitResult {
-		bg.visitCursor(cursor, depth+1)
-		return clang.ChildVisit_Continue
-	})
+// unhandledDeclKindsToIgnore are AST node kinds that walkClangASTNode's
+// switch deliberately leaves unhandled but that aren't themselves a
+// skipped top-level declaration: transparent containers it still
+// recurses through, and child nodes already consumed directly by
+// registerClangJSONStruct/Enum/Function when they read their own
+// "inner" arrays.
+var unhandledDeclKindsToIgnore = map[string]bool{
+	"TranslationUnitDecl": true,
+	"LinkageSpecDecl":     true,
+	"FieldDecl":           true,
+	"ParmVarDecl":         true,
+	"EnumConstantDecl":    true,
 }
 
-// Recursive handler for struct declarations
-func (bg *BindingGenerator) handleCursorStructDecl(cursor clang.Cursor, context string, depth int) {
-	spelling := cursor.Spelling()
-	isAnonymous := spelling == "" || strings.Contains(spelling, "unnamed") || strings.Contains(spelling, " at ")
-	var structName string
+// recordUnhandledDeclKind tallies a top-level declaration kind the
+// clang-CLI backend has no handler for (VarDecl and so on), so PrintUnhandledDeclReport can tell a user which C constructs
+// are silently falling through instead of leaving only scattered debug
+// log lines to piece together. It also remembers one representative
+// name per kind, the first one seen, as a concrete hint for what to add
+// to a --macros/--overrides preamble.
+func (bg *BindingGenerator) recordUnhandledDeclKind(kind string, node map[string]any) {
+	if kind == "" || !strings.HasSuffix(kind, "Decl") || unhandledDeclKindsToIgnore[kind] {
+		return
+	}
+	bg.unhandledDeclKinds[kind]++
+	if _, seen := bg.unhandledDeclExamples[kind]; !seen {
+		if name, _ := node["name"].(string); name != "" {
+			bg.unhandledDeclExamples[kind] = name
+		}
+	}
+}
 
-	if isAnonymous {
-		structName = context
-		if structName == "" {
-			structName = "AnonymousStruct"
+// PrintUnhandledDeclReport prints the top 10 most frequent declaration
+// kinds the clang-CLI backend skipped during the last parse, so users
+// extending the tool (or an overrides file) know where to focus.
+func (bg *BindingGenerator) PrintUnhandledDeclReport() {
+	if len(bg.unhandledDeclKinds) == 0 {
+		return
+	}
+
+	type kindCount struct {
+		Kind  string
+		Count int
+	}
+	counts := make([]kindCount, 0, len(bg.unhandledDeclKinds))
+	for kind, count := range bg.unhandledDeclKinds {
+		counts = append(counts, kindCount{Kind: kind, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
 		}
-		// Always map the Clang spelling to our context-based name
-		if spelling != "" {
-			bg.anonTypeNameMap[spelling] = structName
-			bg.headerLog.WriteString(fmt.Sprintf("[DEBUG] Mapping clang anonymous name '%s' to context name '%s'\n", spelling, structName))
+		return counts[i].Kind < counts[j].Kind
+	})
+
+	fmt.Println("\n=== Unhandled declaration kinds (clang-CLI backend) ===")
+	for i, kc := range counts {
+		if i >= 10 {
+			break
 		}
-	} else {
-		// If this spelling is mapped to a context name, use the context name instead
-		if mapped, ok := bg.anonTypeNameMap[spelling]; ok {
-			structName = mapped
-			bg.headerLog.WriteString(fmt.Sprintf("[DEBUG] Using mapped context name '%s' for clang spelling '%s'\n", structName, spelling))
+		if example, ok := bg.unhandledDeclExamples[kc.Kind]; ok {
+			fmt.Printf("  %4dx %s (e.g. %q)\n", kc.Count, kc.Kind, example)
 		} else {
-			structName = spelling
+			fmt.Printf("  %4dx %s\n", kc.Count, kc.Kind)
 		}
 	}
+}
 
-	// Only register if not already registered under the context name
-	if _, exists := bg.structs[structName]; exists {
-		bg.headerLog.WriteString(fmt.Sprintf("[DEBUG] Skipping already registered struct: %s (spelling: '%s', context: '%s')\n", structName, spelling, context))
-		return // Already processed
+func (bg *BindingGenerator) registerClangJSONStruct(node map[string]any) {
+	name, _ := node["name"].(string)
+	if name == "" {
+		return // Anonymous records need the cgo backend's context tracking
 	}
-
-	bg.headerLog.WriteString(fmt.Sprintf("[DEBUG] Registering struct: %s (spelling: '%s', context: '%s')\n", structName, spelling, context))
-
-	structBinding := StructBinding{
-		Name:   structName,
-		Fields: make([]StructField, 0),
+	if !bg.typeAllowed(name) {
+		return
+	}
+	if _, exists := bg.structs[name]; exists {
+		return
 	}
 
-	// Track seen fields to prevent duplicates during processing
-	seenFields := make(map[string]bool)
+	structBinding := StructBinding{Name: name, Fields: make([]StructField, 0)}
+	separated := false // a zero-width bitfield was just seen
+	inner, _ := node["inner"].([]any)
+	for _, child := range inner {
+		field, ok := child.(map[string]any)
+		if !ok {
+			continue
+		}
+		if fieldKind, _ := field["kind"].(string); fieldKind != "FieldDecl" {
+			continue
+		}
+		fieldName, _ := field["name"].(string)
+		fieldType, _ := field["type"].(map[string]any)
+		qualType, _ := fieldType["qualType"].(string)
 
-	// Process fields
-	cursor.Visit(func(child clang.Cursor, parent clang.Cursor) clang.ChildVisitResult {
-		if child.Kind() == clang.Cursor_FieldDecl {
-			fieldName := child.Spelling()
-			fieldType := child.Type()
-			typeSpelling := fieldType.Spelling()
+		structField := StructField{
+			Name: bg.renameReservedKeywords(fieldName),
+			Type: bg.mapCTypeToNature(qualType),
+		}
 
-			// Canonicalize anonymous type names
-			if mapped, ok := bg.anonTypeNameMap[typeSpelling]; ok {
+		if isBitfield, _ := field["isBitfield"].(bool); isBitfield {
+			if literal, ok := jsonInitializerLiteral(field); ok {
+				if width, err := evalConstExpr(literal, bg.lookupConstantValue); err == nil {
+					if width == 0 {
+						// `int : 0;` is a zero-width separator, not a
+						// real field: no name, no storage of its own -
+						// it just forces whatever bitfields follow into
+						// a fresh storage unit (see the matching case in
+						// handleCursorStructDecl).
+						separated = true
+						continue
+					}
+					structField.BitWidth = int(width)
+					if len(structBinding.Fields) > 0 {
+						prev := structBinding.Fields[len(structBinding.Fields)-1]
+						if !separated && prev.BitWidth > 0 && prev.Type == structField.Type {
+							structField.BitOffset = prev.BitOffset + prev.BitWidth
+						}
+					}
+					separated = false
+				} else {
+					fmt.Printf("Warning: could not evaluate bitfield width for %s.%s = %q: %v\n", name, fieldName, literal, err)
+				}
+			}
+		}
+
+		structBinding.Fields = append(structBinding.Fields, structField)
+	}
+
+	bg.structs[name] = structBinding
+	bg.env.DefineTag(name, name)
+}
+
+func (bg *BindingGenerator) registerClangJSONEnum(node map[string]any) {
+	name, _ := node["name"].(string)
+	if !bg.typeAllowed(name) {
+		return
+	}
+	enumBinding := EnumBinding{Name: name, Members: make([]EnumMember, 0)}
+
+	inner, _ := node["inner"].([]any)
+	nextValue := 0
+	for _, child := range inner {
+		constant, ok := child.(map[string]any)
+		if !ok {
+			continue
+		}
+		if constKind, _ := constant["kind"].(string); constKind != "EnumConstantDecl" {
+			continue
+		}
+		constName, _ := constant["name"].(string)
+
+		value := nextValue
+		if literal, ok := jsonInitializerLiteral(constant); ok {
+			if evaluated, err := evalConstExpr(literal, bg.lookupConstantValue); err == nil {
+				value = int(evaluated)
+			} else {
+				fmt.Printf("Warning: could not evaluate enum constant %s = %q: %v\n", constName, literal, err)
+			}
+		}
+
+		enumBinding.Members = append(enumBinding.Members, EnumMember{Name: constName, Value: value})
+		bg.constantValues[constName] = value
+		nextValue = value + 1
+	}
+
+	bg.enums[name] = enumBinding
+	bg.env.DefineEnum(name)
+}
+
+// jsonInitializerLiteral searches a clang -ast-dump=json node (an
+// EnumConstantDecl's initializer, or a FieldDecl's bitfield width) for
+// the literal/expression text of its child expression, e.g. the "8" in
+// `FOO = 1 << 3` or the "3" in `unsigned flags : 3` (clang reports the
+// folded "value" on the innermost ConstantExpr/IntegerLiteral node). It
+// reports false when there is no such child expression.
+func jsonInitializerLiteral(constant map[string]any) (string, bool) {
+	inner, _ := constant["inner"].([]any)
+	for _, c := range inner {
+		child, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		if value, ok := child["value"].(string); ok && value != "" {
+			return value, true
+		}
+		if literal, ok := jsonInitializerLiteral(child); ok {
+			return literal, true
+		}
+	}
+	return "", false
+}
+
+func (bg *BindingGenerator) registerClangJSONFunction(node map[string]any) {
+	name, _ := node["name"].(string)
+	if name == "" {
+		return
+	}
+	if !bg.functionAllowed(name) {
+		return
+	}
+
+	fnType, _ := node["type"].(map[string]any)
+	qualType, _ := fnType["qualType"].(string)
+	returnType := "void"
+	paramListSpelling := ""
+	if idx := strings.Index(qualType, "("); idx > 0 {
+		returnType = bg.mapCTypeToNature(strings.TrimSpace(qualType[:idx]))
+		if closeIdx := strings.LastIndex(qualType, ")"); closeIdx > idx {
+			paramListSpelling = strings.TrimSpace(qualType[idx+1 : closeIdx])
+		}
+	}
+
+	var parameters []Parameter
+	inner, _ := node["inner"].([]any)
+	for i, child := range inner {
+		param, ok := child.(map[string]any)
+		if !ok {
+			continue
+		}
+		if paramKind, _ := param["kind"].(string); paramKind != "ParmVarDecl" {
+			continue
+		}
+		paramName, _ := param["name"].(string)
+		if paramName == "" {
+			paramName = fmt.Sprintf("arg%d", i)
+		}
+		paramType, _ := param["type"].(map[string]any)
+		paramQualType, _ := paramType["qualType"].(string)
+
+		parameters = append(parameters, Parameter{
+			Name: bg.renameReservedKeywords(paramName),
+			Type: bg.mapCTypeToNature(paramQualType),
+		})
+	}
+
+	if len(parameters) == 0 && paramListSpelling != "" && paramListSpelling != "void" {
+		// `int foo();` (unspecified parameter list) differs from
+		// `int foo(void)` (explicitly zero parameters) — this is the
+		// old-style K&R forward-declaration form. The JSON AST alone has
+		// no parameter types for it, but a K&R *definition* elsewhere in
+		// the same header spells them out as "type name;" lines before
+		// its body - see findKRParameters in kr.go.
+		if krParams, ok := bg.findKRParameters(bg.includedFiles, name); ok {
+			for _, p := range krParams {
+				parameters = append(parameters, Parameter{
+					Name: bg.renameReservedKeywords(p.Name),
+					Type: bg.mapCTypeToNature(p.Type),
+				})
+			}
+		} else {
+			fmt.Printf("Warning: function %s has an old-style K&R parameter list %q; assuming zero arguments, which may not match its real signature\n", name, paramListSpelling)
+		}
+	}
+
+	bg.functions[name] = FunctionBinding{
+		Name:       name,
+		CName:      name,
+		Parameters: parameters,
+		ReturnType: returnType,
+	}
+}
+
+// registerClangJSONTypedef registers a typedef from the clang-CLI JSON AST,
+// mirroring handleTypedefDecl's two cases for the cgo backend: a function
+// pointer typedef's qualType (e.g. "int (*)(void *, SDL_Event *)") is
+// parsed via parseFuncPtrType to preserve its real signature, anything
+// else falls through to the normal mapCTypeToNature resolution.
+func (bg *BindingGenerator) registerClangJSONTypedef(node map[string]any) {
+	name, _ := node["name"].(string)
+	if name == "" {
+		return
+	}
+
+	typedefType, _ := node["type"].(map[string]any)
+	qualType, _ := typedefType["qualType"].(string)
+	if qualType == "" {
+		return
+	}
+
+	natureType := qualType
+	if fp, ok := bg.parseFuncPtrType(qualType); ok {
+		natureType = fp.natureSignature()
+	} else {
+		natureType = bg.mapCTypeToNature(qualType)
+	}
+
+	bg.typeMappings[name] = TypeMapping{
+		CType:      name,
+		NatureType: natureType,
+		IsPointer:  false,
+	}
+	bg.env.DefineTypedef(name, natureType)
+}
+
+// visitCursor recursively visits all cursors in the AST
+func (bg *BindingGenerator) visitCursor(cursor clang.Cursor, depth int) {
+	// Skip system headers
+	if cursor.Location().IsInSystemHeader() {
+		return
+	}
+
+	// Check if cursor has already been processed
+	if _, exists := bg.processedCursors[cursor]; exists {
+		return
+	}
+	bg.processedCursors[cursor] = true
+
+	kind := cursor.Kind()
+	spelling := cursor.Spelling()
+
+	bg.headerLog.WriteString(fmt.Sprintf("%s[DEBUG] Visiting cursor: %s (%s) at depth %d\n",
+		strings.Repeat("  ", depth), spelling, kind.String(), depth))
+
+	switch kind {
+	case clang.Cursor_StructDecl:
+		// For anonymous structs, we need to find a proper context
+		if spelling == "" || strings.Contains(spelling, "unnamed") || strings.Contains(spelling, " at ") {
+			// This is an anonymous struct, we need to find its parent context
+			parent := cursor.SemanticParent()
+			if parent.Kind() == clang.Cursor_TypedefDecl {
+				// This is a typedef struct, use the typedef name as context
+				typedefName := parent.Spelling()
+				bg.handleCursorStructDecl(cursor, typedefName, depth)
+			} else {
+				// A truly anonymous struct (no enclosing typedef to name
+				// it after): derive a stable name from where it's
+				// declared instead of a bare "AnonymousStruct" literal,
+				// so two distinct anonymous structs don't collide under
+				// one name and the same header yields the same names
+				// regardless of parse order.
+				bg.handleCursorStructDecl(cursor, bg.anonLocationName(cursor, "AnonymousStruct"), depth)
+			}
+		} else {
+			bg.handleCursorStructDecl(cursor, spelling, depth)
+		}
+	case clang.Cursor_FieldDecl:
+		bg.handleFieldDecl(cursor, nil, depth)
+	case clang.Cursor_TypedefDecl:
+		bg.handleTypedefDecl(cursor, depth)
+	case clang.Cursor_FunctionDecl:
+		bg.handleFunctionDecl(cursor, depth)
+	case clang.Cursor_EnumDecl:
+		bg.handleEnumDecl(cursor, depth)
+	case clang.Cursor_UnionDecl:
+		// For anonymous unions, we need to find a proper context
+		if spelling == "" || strings.Contains(spelling, "unnamed") || strings.Contains(spelling, " at ") {
+			// This is an anonymous union, we need to find its parent context
+			parent := cursor.SemanticParent()
+			if parent.Kind() == clang.Cursor_TypedefDecl {
+				// This is a typedef union, use the typedef name as context
+				typedefName := parent.Spelling()
+				bg.handleCursorUnionDecl(cursor, typedefName, depth)
+			} else {
+				// See the matching comment in the StructDecl case above.
+				bg.handleCursorUnionDecl(cursor, bg.anonLocationName(cursor, "AnonymousUnion"), depth)
+			}
+		} else {
+			bg.handleCursorUnionDecl(cursor, spelling, depth)
+		}
+	case clang.Cursor_MacroDefinition: // Only call handleMacroDefinition for actual macros
+		literalType := cursor.Type() // Although for MacroDefinition, this might not be strictly a "literal type" but the underlying type of the macro's expansion if it's a constant.
+		bg.handleMacroDefinition(cursor, depth, literalType)
+	case clang.Cursor_InclusionDirective:
+		bg.handleIncludeDirective(cursor, depth)
+	default:
+		bg.headerLog.WriteString(fmt.Sprintf("%s[DEBUG] Unknown cursor kind: %s, %d\n", strings.Repeat("  ", depth), kind.String(), int(kind)))
+		initalizerCursor := cursor.VarDeclInitializer()
+
+		if !initalizerCursor.IsNull() {
+			kind = initalizerCursor.Kind()
+		}
+	}
+
+	// Visit children
+	cursor.Visit(func(cursor, parent clang.Cursor) clang.ChildVisitResult {
+		bg.visitCursor(cursor, depth+1)
+		return clang.ChildVisit_Continue
+	})
+}
+
+// Recursive handler for struct declarations
+func (bg *BindingGenerator) handleCursorStructDecl(cursor clang.Cursor, context string, depth int) {
+	spelling := cursor.Spelling()
+	isAnonymous := spelling == "" || strings.Contains(spelling, "unnamed") || strings.Contains(spelling, " at ")
+	var structName string
+
+	if isAnonymous {
+		structName = context
+		if structName == "" {
+			structName = "AnonymousStruct"
+		}
+		// Always map the Clang spelling to our context-based name
+		if spelling != "" {
+			bg.anonTypeNameMap[spelling] = structName
+			bg.headerLog.WriteString(fmt.Sprintf("[DEBUG] Mapping clang anonymous name '%s' to context name '%s'\n", spelling, structName))
+		}
+	} else {
+		// If this spelling is mapped to a context name, use the context name instead
+		if mapped, ok := bg.anonTypeNameMap[spelling]; ok {
+			structName = mapped
+			bg.headerLog.WriteString(fmt.Sprintf("[DEBUG] Using mapped context name '%s' for clang spelling '%s'\n", structName, spelling))
+		} else {
+			structName = spelling
+		}
+	}
+
+	if !isAnonymous && (!bg.locationAllowed(cursor) || !bg.typeAllowed(structName) || !bg.genericSymbolAllowed(structName)) {
+		return
+	}
+
+	// Only register if not already registered under the context name
+	if _, exists := bg.structs[structName]; exists {
+		bg.headerLog.WriteString(fmt.Sprintf("[DEBUG] Skipping already registered struct: %s (spelling: '%s', context: '%s')\n", structName, spelling, context))
+		return // Already processed
+	}
+
+	bg.headerLog.WriteString(fmt.Sprintf("[DEBUG] Registering struct: %s (spelling: '%s', context: '%s')\n", structName, spelling, context))
+
+	structBinding := StructBinding{
+		Name:   structName,
+		Fields: make([]StructField, 0),
+	}
+
+	// Track seen fields to prevent duplicates during processing
+	seenFields := make(map[string]bool)
+
+	// Track the current bitfield storage unit: its declared type and how
+	// many bits of it are already spoken for. A new unit starts whenever
+	// the storage type changes or the next field wouldn't fit.
+	var bitUnitType string
+	var bitUnitOffset int
+
+	// Process fields
+	cursor.Visit(func(child clang.Cursor, parent clang.Cursor) clang.ChildVisitResult {
+		if child.Kind() == clang.Cursor_FieldDecl {
+			fieldName := child.Spelling()
+			fieldType := child.Type()
+			typeSpelling := fieldType.Spelling()
+
+			// Canonicalize anonymous type names
+			if mapped, ok := bg.anonTypeNameMap[typeSpelling]; ok {
 				bg.headerLog.WriteString(fmt.Sprintf("[DEBUG] Canonicalizing field type '%s' to '%s' for field '%s' in struct '%s'\n", typeSpelling, mapped, fieldName, structName))
 				typeSpelling = mapped
 			}
@@ -606,6 +1428,38 @@ func (bg *BindingGenerator) handleCursorStructDecl(cursor clang.Cursor, context
 			}
 			seenFields[fieldKey] = true
 
+			if child.IsBitField() {
+				width := int(child.FieldDeclBitWidth())
+				unitBits := int(fieldType.SizeOf()) * 8
+
+				if width == 0 {
+					// `int : 0;` is a zero-width separator, not a real
+					// field: it has no name and no storage of its own,
+					// its only effect is forcing whatever bitfields
+					// follow it into a fresh storage unit even if the
+					// current one still has room.
+					bitUnitType, bitUnitOffset = "", 0
+					return clang.ChildVisit_Continue
+				}
+
+				if typeSpelling != bitUnitType || bitUnitOffset+width > unitBits {
+					bitUnitType = typeSpelling
+					bitUnitOffset = 0
+				}
+
+				structBinding.Fields = append(structBinding.Fields, StructField{
+					Name:      bg.renameReservedKeywords(fieldName),
+					Type:      bg.mapCTypeToNature(typeSpelling),
+					BitWidth:  width,
+					BitOffset: bitUnitOffset,
+				})
+				bitUnitOffset += width
+				return clang.ChildVisit_Continue
+			}
+			// A non-bitfield field starts a fresh storage unit for any
+			// bitfields that follow it.
+			bitUnitType, bitUnitOffset = "", 0
+
 			if fieldType.CanonicalType().Kind() == clang.Type_Record {
 				declKind := fieldType.Declaration().Kind()
 
@@ -619,16 +1473,38 @@ func (bg *BindingGenerator) handleCursorStructDecl(cursor clang.Cursor, context
 						Type: childContext,
 					})
 				case clang.Cursor_UnionDecl:
-					// Nested union
-					childContext := structName + "_" + fieldName + "_Union"
-					bg.handleCursorUnionDecl(fieldType.Declaration(), childContext, depth+1)
-					// Use size-based name for union
-					unionSize := fieldType.SizeOf()
-					unionTypeName := "Union_" + num2words.Convert(int(unionSize)) + "_bytes"
-					structBinding.Fields = append(structBinding.Fields, StructField{
-						Name: bg.renameReservedKeywords(fieldName),
-						Type: unionTypeName,
-					})
+					unionTypeName := unionDeclTypeName(fieldType.Declaration(), fieldType.SizeOf())
+
+					if fieldName == "" {
+						// An anonymous union member: its fields are
+						// accessed directly on the enclosing struct in C
+						// (s.member, not s.anon.member), so inline its
+						// accessors onto structName instead of adding a
+						// named sub-field the user has no C-side name for.
+						childContext := fmt.Sprintf("%s_AnonUnion%d", structName, len(bg.anonUnions[structName]))
+						bg.handleCursorUnionDecl(fieldType.Declaration(), childContext, depth+1)
+
+						backingField := fmt.Sprintf("_anon_union%d", len(bg.anonUnions[structName]))
+						structBinding.Fields = append(structBinding.Fields, StructField{
+							Name: backingField,
+							Type: unionTypeName,
+						})
+						bg.anonUnions[structName] = append(bg.anonUnions[structName], anonUnionRef{
+							BackingField:  backingField,
+							UnionTypeName: unionTypeName,
+							UnionSize:     fieldType.SizeOf(),
+							Discriminant:  bg.findUnionDiscriminant(structBinding.Fields),
+						})
+						bg.updateStructInMap(&structBinding)
+					} else {
+						// Nested, named union
+						childContext := structName + "_" + fieldName + "_Union"
+						bg.handleCursorUnionDecl(fieldType.Declaration(), childContext, depth+1)
+						structBinding.Fields = append(structBinding.Fields, StructField{
+							Name: bg.renameReservedKeywords(fieldName),
+							Type: unionTypeName,
+						})
+					}
 				default:
 					natureType := bg.mapCTypeToNature(typeSpelling)
 					structBinding.Fields = append(structBinding.Fields, StructField{
@@ -671,14 +1547,36 @@ func (bg *BindingGenerator) handleCursorStructDecl(cursor clang.Cursor, context
 		return clang.ChildVisit_Continue
 	})
 
+	if canonical, found := bg.dedupeAnonStruct(structName, structBinding.Fields); found {
+		bg.headerLog.WriteString(fmt.Sprintf("[DEBUG] Deduplicating anonymous struct %s into existing %s\n", structName, canonical))
+		return
+	}
+
 	bg.structs[structName] = structBinding
+	bg.env.DefineTag(structName, structName)
 }
 
 // Recursive handler for union declarations
+// unionDeclTypeName returns the Nature type name a union declaration
+// cursor registers and is referenced under: its own C tag for a named
+// union ("union Foo" -> "Foo"), so two differently-named unions that
+// happen to share a byte size stay distinct, or the previous size-based
+// synthetic name ("Union_N_bytes") as a fallback for a genuinely
+// anonymous union with no tag to use.
+func unionDeclTypeName(declCursor clang.Cursor, size int64) string {
+	if tag := declCursor.Spelling(); tag != "" {
+		return tag
+	}
+	return "Union_" + num2words.Convert(int(size)) + "_bytes"
+}
+
 func (bg *BindingGenerator) handleCursorUnionDecl(cursor clang.Cursor, context string, depth int) {
 	unionSize := cursor.Type().SizeOf()
-	unionTypeName := "Union_" + num2words.Convert(int(unionSize)) + "_bytes"
-	if _, exists := bg.unions[unionSize]; exists {
+	unionTypeName := unionDeclTypeName(cursor, unionSize)
+	if !bg.locationAllowed(cursor) || !bg.typeAllowed(unionTypeName) || !bg.genericSymbolAllowed(unionTypeName) {
+		return
+	}
+	if _, exists := bg.unions[unionTypeName]; exists {
 		bg.headerLog.WriteString(fmt.Sprintf("[DEBUG] Skipping already registered union: %s (context: '%s')\n", unionTypeName, context))
 		return // Already processed
 	}
@@ -708,8 +1606,7 @@ func (bg *BindingGenerator) handleCursorUnionDecl(cursor clang.Cursor, context s
 					// Nested union in union
 					childContext := unionTypeName + "_" + fieldName + "_Union"
 					bg.handleCursorUnionDecl(fieldType.Declaration(), childContext, depth+1)
-					nestedUnionSize := fieldType.SizeOf()
-					nestedUnionTypeName := "Union_" + num2words.Convert(int(nestedUnionSize)) + "_bytes"
+					nestedUnionTypeName := unionDeclTypeName(fieldType.Declaration(), fieldType.SizeOf())
 					unionFields = append(unionFields, StructField{
 						Name: bg.renameReservedKeywords(fieldName),
 						Type: nestedUnionTypeName,
@@ -725,7 +1622,7 @@ func (bg *BindingGenerator) handleCursorUnionDecl(cursor clang.Cursor, context s
 		}
 		return clang.ChildVisit_Continue
 	})
-	bg.unions[unionSize] = NewUnionBinding(unionTypeName, unionSize, unionFields)
+	bg.unions[unionTypeName] = NewUnionBinding(unionTypeName, unionSize, unionFields)
 	bg.unionNames[unionTypeName] = unionSize
 }
 
@@ -795,9 +1692,7 @@ func (bg *BindingGenerator) handleFieldDecl(cursor clang.Cursor, structBinding *
 		// Get the union declaration
 		fieldCursor := cursor.Type().Declaration()
 		if fieldCursor.Kind() == clang.Cursor_UnionDecl {
-			// Always use the size-based union type name
-			unionSize := fieldType.SizeOf()
-			unionTypeName := fmt.Sprintf("Union_%s_bytes", num2words.Convert(int(unionSize)))
+			unionTypeName := unionDeclTypeName(fieldCursor, fieldType.SizeOf())
 			// Recursively process the union
 			bg.handleCursorUnionDecl(fieldCursor, unionTypeName, depth+1)
 			// Add the union field to the struct
@@ -878,6 +1773,7 @@ func (bg *BindingGenerator) updateStructInMap(structBinding *StructBinding) {
 	structBinding.Fields = deduplicatedFields
 
 	bg.structs[structBinding.Name] = *structBinding
+	bg.env.DefineTag(structBinding.Name, structBinding.Name)
 	bg.headerLog.WriteString(fmt.Sprintf("Added struct %s to map with %d fields\n",
 		structBinding.Name, len(structBinding.Fields)))
 }
@@ -905,6 +1801,9 @@ func (bg *BindingGenerator) deduplicateStructFields(fields []StructField) []Stru
 // handleTypedefDecl handles typedef declarations
 func (bg *BindingGenerator) handleTypedefDecl(cursor clang.Cursor, depth int) {
 	typedefName := cursor.Spelling()
+	if !bg.locationAllowed(cursor) || !bg.genericSymbolAllowed(typedefName) {
+		return
+	}
 	underlyingType := cursor.TypedefDeclUnderlyingType()
 	typeSpelling := underlyingType.Spelling()
 
@@ -921,6 +1820,7 @@ func (bg *BindingGenerator) handleTypedefDecl(cursor clang.Cursor, depth int) {
 			// Create function pointer type mapping
 			natureType := "fn("
 			var paramTypes []string
+			var parameters []Parameter
 
 			numParams := pointeeType.NumArgTypes()
 			for i := uint32(0); i < uint32(numParams); i++ {
@@ -928,6 +1828,10 @@ func (bg *BindingGenerator) handleTypedefDecl(cursor clang.Cursor, depth int) {
 				paramTypeSpelling := paramType.Spelling()
 				natureParamType := bg.mapCTypeToNature(paramTypeSpelling)
 				paramTypes = append(paramTypes, natureParamType)
+				parameters = append(parameters, Parameter{
+					Name: bg.renameReservedKeywords(fmt.Sprintf("arg%d", i)),
+					Type: natureParamType,
+				})
 			}
 
 			natureType += strings.Join(paramTypes, ", ")
@@ -943,6 +1847,20 @@ func (bg *BindingGenerator) handleTypedefDecl(cursor clang.Cursor, depth int) {
 				NatureType: natureType,
 				IsPointer:  false,
 			}
+			bg.env.DefineTypedef(typedefName, natureType)
+
+			// Record under its own typedef name too (parseFuncPtrType/
+			// mapCTypeToNature's funcPtrTypes cache is keyed by raw C
+			// spelling, which a named typedef like this never goes
+			// through), so writeCallbackShims can emit a
+			// register_<Typedef>_cb/free_<Typedef>_cb pair callers can
+			// invoke by the typedef's own name instead of having to
+			// derive its signature identifier themselves.
+			bg.callbackTypedefs[typedefName] = FuncPtrBinding{
+				Name:       typedefName,
+				ReturnType: natureReturnType,
+				Parameters: parameters,
+			}
 			return
 		}
 	}
@@ -953,8 +1871,8 @@ func (bg *BindingGenerator) handleTypedefDecl(cursor clang.Cursor, depth int) {
 	// If this is a typedef for a union, map it directly to the union type name
 	if strings.HasPrefix(typeSpelling, "union ") {
 		unionName := strings.TrimSpace(strings.TrimPrefix(typeSpelling, "union "))
-		if unionSize, exists := bg.unionNames[unionName]; exists {
-			natureType = fmt.Sprintf("Union_%s_bytes", num2words.Convert(int(unionSize)))
+		if _, exists := bg.unionNames[unionName]; exists {
+			natureType = unionName
 			bg.headerLog.WriteString(fmt.Sprintf("%sMapped union typedef %s to %s\n",
 				strings.Repeat("  ", depth), typedefName, natureType))
 		}
@@ -965,6 +1883,7 @@ func (bg *BindingGenerator) handleTypedefDecl(cursor clang.Cursor, depth int) {
 		NatureType: natureType,
 		IsPointer:  false,
 	}
+	bg.env.DefineTypedef(typedefName, natureType)
 }
 
 // handleFunctionDecl handles function declarations
@@ -973,6 +1892,9 @@ func (bg *BindingGenerator) handleFunctionDecl(cursor clang.Cursor, depth int) {
 	if funcName == "" {
 		return // Skip unnamed functions
 	}
+	if !bg.locationAllowed(cursor) || !bg.functionAllowed(funcName) || !bg.genericSymbolAllowed(funcName) {
+		return
+	}
 
 	bg.headerLog.WriteString(fmt.Sprintf("%sFound function: %s\n", strings.Repeat("  ", depth), funcName))
 
@@ -983,6 +1905,7 @@ func (bg *BindingGenerator) handleFunctionDecl(cursor clang.Cursor, depth int) {
 
 	// Get parameters
 	var parameters []Parameter
+	var fixedParamCTypes []string
 	numParams := int(cursor.NumArguments())
 	for i := 0; i < numParams; i++ {
 		param := cursor.Argument(uint32(i))
@@ -999,33 +1922,51 @@ func (bg *BindingGenerator) handleFunctionDecl(cursor clang.Cursor, depth int) {
 			Name: bg.renameReservedKeywords(paramName),
 			Type: natureParamType,
 		})
+		fixedParamCTypes = append(fixedParamCTypes, paramTypeSpelling)
+	}
+
+	if numParams == 0 && cursor.Type().Kind() == clang.Type_FunctionNoProto {
+		// An old-style K&R declaration (`int foo();` with no `(void)`)
+		// exposes no parameter types to libclang either, unlike a full
+		// K&R *definition* where the separate type lines give it real
+		// types - recover those by re-scanning the header text directly
+		// (see findKRParameters in kr.go) before falling back to warning.
+		if krParams, ok := bg.findKRParameters(bg.includedFiles, funcName); ok {
+			for _, p := range krParams {
+				parameters = append(parameters, Parameter{
+					Name: bg.renameReservedKeywords(p.Name),
+					Type: bg.mapCTypeToNature(p.Type),
+				})
+				fixedParamCTypes = append(fixedParamCTypes, p.Type)
+			}
+		} else {
+			fmt.Printf("Warning: function %s has an old-style K&R parameter list; assuming zero arguments, which may not match its real signature\n", funcName)
+		}
 	}
 
-	// Check if function is variadic by examining the function type
-	isVariadic := false
-	var variadicType string
+	// A variadic function gets its fixed-argument call registered as a
+	// normal FunctionBinding below (a plain C call with zero varargs is
+	// always valid), plus a VariadicFunction record so
+	// writeVariadicWrappers/variadicShimSource can emit the arity-1..N
+	// monomorphized wrappers real varargs calls need - Nature/most FFI
+	// ABIs have no calling convention for a single "...[T]" slice
+	// parameter, which is what this registered before.
 	if cursor.IsVariadic() {
-		isVariadic = true
 		bg.headerLog.WriteString(fmt.Sprintf("%sFound variadic function: %s\n", strings.Repeat("  ", depth), funcName))
 
 		variadicArg := cursor.Argument(uint32(cursor.NumArguments()))
-
-		bg.headerLog.WriteString(fmt.Sprintf("%sVariadic argument: name: %s, type: %s\n", strings.Repeat("  ", depth), variadicArg.Spelling(), variadicArg.Type().Spelling()))
-
-		// just to make sure, print the arg at index 1
-		arg1 := cursor.Argument(uint32(1))
-		bg.headerLog.WriteString(fmt.Sprintf("%sArg 1: name: %s, type: %s\n", strings.Repeat("  ", depth), arg1.Spelling(), arg1.Type().Spelling()))
-
-		bg.headerLog.WriteString(fmt.Sprintf("%s\n", strconv.Itoa(int(cursor.NumArguments()))))
-
-		variadicType = bg.mapCTypeToNature(variadicArg.Type().Spelling())
-	}
-
-	if isVariadic {
-		parameters = append(parameters, Parameter{
-			Name: "args",
-			Type: fmt.Sprintf("...[%s]", variadicType),
-		})
+		variadicCType := variadicArg.Type().Spelling()
+		variadicType := bg.mapCTypeToNature(variadicCType)
+
+		bg.variadicFunctions[funcName] = VariadicFunction{
+			CName:            funcName,
+			FixedParams:      parameters,
+			FixedParamCTypes: fixedParamCTypes,
+			VariadicType:     variadicType,
+			VariadicCType:    variadicCType,
+			ReturnType:       natureReturnType,
+			ReturnCType:      returnTypeSpelling,
+		}
 	}
 
 	bg.functions[funcName] = FunctionBinding{
@@ -1041,6 +1982,9 @@ func (bg *BindingGenerator) handleFunctionDecl(cursor clang.Cursor, depth int) {
 // handleEnumDecl handles enum declarations
 func (bg *BindingGenerator) handleEnumDecl(cursor clang.Cursor, depth int) {
 	enumName := cursor.Spelling()
+	if !bg.locationAllowed(cursor) || !bg.typeAllowed(enumName) || !bg.genericSymbolAllowed(enumName) {
+		return
+	}
 	bg.headerLog.WriteString(fmt.Sprintf("%sFound enum: %s\n", strings.Repeat("  ", depth), enumName))
 
 	enumBinding := EnumBinding{
@@ -1066,6 +2010,7 @@ func (bg *BindingGenerator) handleEnumDecl(cursor clang.Cursor, depth int) {
 	})
 
 	bg.enums[enumName] = enumBinding
+	bg.env.DefineEnum(enumName)
 	bg.headerLog.WriteString(fmt.Sprintf("%sAdded enum: %s with %d members\n",
 		strings.Repeat("  ", depth), enumName, len(enumBinding.Members)))
 }
@@ -1074,6 +2019,9 @@ func (bg *BindingGenerator) handleEnumDecl(cursor clang.Cursor, depth int) {
 func (bg *BindingGenerator) handleMacroDefinition(cursor clang.Cursor, depth int, kind clang.Type) {
 	bg.headerLog.WriteString(fmt.Sprintf("%sFound macro: %s\n", strings.Repeat("  ", depth), cursor.Spelling()))
 	macroName := cursor.Spelling()
+	if !bg.locationAllowed(cursor) || !bg.genericSymbolAllowed(macroName) {
+		return
+	}
 
 	// Get the macro value by reading the source file
 	var macroValue string = "0"  // Default value
@@ -1149,6 +2097,13 @@ func (bg *BindingGenerator) handleMacroDefinition(cursor clang.Cursor, depth int
 		macroType = "f64"
 	} else {
 		macroType = "i32"
+		if evaluated, err := evalConstExpr(macroValue, bg.lookupConstantValue); err == nil {
+			bg.constantValues[macroName] = int(evaluated)
+			macroType = inferConstantType(macroValue, evaluated)
+			macroValue = strconv.FormatInt(evaluated, 10)
+		} else {
+			fmt.Printf("Warning: could not evaluate macro %s = %q: %v\n", macroName, macroValue, err)
+		}
 	}
 
 	bg.constants[macroName] = ConstantItem{
@@ -1246,109 +2201,146 @@ func (bg *BindingGenerator) sortConstantsByDependencies() []ConstantItem {
 	return result
 }
 
-// extractConstantDependencies extracts constant names from a constant value
+// extractConstantDependencies extracts the names of other known constants
+// referenced in value. It tokenizes with cevalTokenize (the same
+// identifier scanner ceval.go uses to evaluate these expressions) rather
+// than a `[A-Z][A-Z0-9_]*`-only regex, so a dependency on a mixed-case or
+// lowercase constant name isn't silently missed.
 func (bg *BindingGenerator) extractConstantDependencies(value string) []string {
 	var deps []string
 
-	// Use regex to find potential constant names
-	// Look for word boundaries to avoid partial matches
-	re := regexp.MustCompile(`\b([A-Z][A-Z0-9_]*)\b`)
-	matches := re.FindAllStringSubmatch(value, -1)
-
-	for _, match := range matches {
-		constantName := match[1]
-		// Check if this is actually a constant we know about
-		if _, exists := bg.constants[constantName]; exists {
-			deps = append(deps, constantName)
+	for _, tok := range cevalTokenize(value) {
+		if tok == "" || !isIdentStart(rune(tok[0])) {
+			continue
+		}
+		if _, exists := bg.constants[tok]; exists {
+			deps = append(deps, tok)
 		}
 	}
 
 	return deps
 }
 
-// generateNatureBindings generates Nature binding code
-func (bg *BindingGenerator) generateNatureBindings() string {
-	var sb strings.Builder
-
-	// Header comment
-	sb.WriteString("// Generated Nature bindings\n")
-	sb.WriteString("// This file was automatically generated by naturebindgen\n\n")
+// reachabilityFilter returns an isReachable predicate reflecting
+// --only-reachable: nil (every name considered reachable) when the flag
+// isn't set, otherwise bg.reachableTypes()'s membership test. Shared by
+// generateNatureBindings and writeSplit so --split's per-category files
+// drop the same declarations the single-file output would.
+func (bg *BindingGenerator) reachabilityFilter() func(name string) bool {
+	var reachable map[string]bool
+	if bg.onlyReachable {
+		reachable = bg.reachableTypes()
+	}
+	return func(name string) bool {
+		return reachable == nil || reachable[name]
+	}
+}
 
-	// Generate constants in dependency order
-	if len(bg.constants) > 0 {
-		sb.WriteString("// Constants\n")
-		sortedConstants := bg.sortConstantsByDependencies()
-		for _, constant := range sortedConstants {
-			sb.WriteString(fmt.Sprintf("%s %s = %s\n", constant.Type, constant.Name, constant.Value))
+// writeConstantsSection emits the "// Constants" block, in dependency order.
+func (bg *BindingGenerator) writeConstantsSection(sb *strings.Builder) {
+	if len(bg.constants) == 0 {
+		return
+	}
+	sb.WriteString("// Constants\n")
+	sortedConstants := bg.sortConstantsByDependencies()
+	for _, constant := range sortedConstants {
+		if bg.isSkipped(constant.Name) {
+			continue
 		}
-		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf("%s %s = %s\n", constant.Type, bg.renameSymbol(constant.Name), constant.Value))
 	}
+	sb.WriteString("\n")
+}
 
-	// Generate enum constants
-	if len(bg.enums) > 0 {
-		sb.WriteString("// Enum constants\n")
-		for _, enum := range bg.enums {
-			for _, member := range enum.Members {
-				sb.WriteString(fmt.Sprintf("int %s_C_ENUM_%s = %d\n", enum.Name, member.Name, member.Value))
-			}
+// writeEnumConstantsSection emits the "// Enum constants" block: every
+// enum member as a top-level int constant, since Nature has no enum type
+// of its own.
+func (bg *BindingGenerator) writeEnumConstantsSection(sb *strings.Builder) {
+	if len(bg.enums) == 0 {
+		return
+	}
+	sb.WriteString("// Enum constants\n")
+	for _, enum := range bg.enums {
+		for _, member := range enum.Members {
+			sb.WriteString(fmt.Sprintf("int %s_C_ENUM_%s = %d\n", enum.Name, member.Name, member.Value))
 		}
-		sb.WriteString("\n")
 	}
+	sb.WriteString("\n")
+}
 
-	// Generate type definitions (including function pointer typedefs)
-	if len(bg.typeMappings) > 0 {
-		sb.WriteString("// Type definitions\n")
-		for cType, mapping := range bg.typeMappings {
-			// Skip basic type mappings that are just direct conversions
-			if cType == mapping.NatureType {
-				continue
-			}
-			// Only output function pointer typedefs and custom types
-			if strings.HasPrefix(mapping.NatureType, "fn(") {
-				sb.WriteString(fmt.Sprintf("type %s = %s\n", cType, mapping.NatureType))
-			}
+// writeTypeDefsSection emits the "// Type definitions" block: every
+// typedef actually declared in the parsed header (as opposed to the seed
+// table of builtin C type names initializeTypeMappings preloads into the
+// same map), including function pointer typedefs, filtered by isReachable.
+func (bg *BindingGenerator) writeTypeDefsSection(sb *strings.Builder, isReachable func(string) bool) {
+	if len(bg.typeMappings) == 0 {
+		return
+	}
+	sb.WriteString("// Type definitions\n")
+	for cType, mapping := range bg.typeMappings {
+		if cType == mapping.NatureType {
+			continue
+		}
+		if _, isTypedef := bg.env.LookupTypedef(cType); isTypedef && isReachable(cType) {
+			sb.WriteString(fmt.Sprintf("type %s = %s\n", cType, mapping.NatureType))
 		}
-		sb.WriteString("\n")
 	}
+	sb.WriteString("\n")
+}
 
-	// Generate union definitions first (before structs that reference them)
+// writeUnionStructSection emits the "// Union definitions" and
+// "// Struct definitions" blocks, in that order since a struct's fields
+// may reference a union defined just above it, both filtered by
+// isReachable.
+func (bg *BindingGenerator) writeUnionStructSection(sb *strings.Builder, isReachable func(string) bool) {
 	if len(bg.unions) > 0 {
 		sb.WriteString("// Union definitions\n")
 		for _, union := range bg.unions {
+			if !isReachable(union.Name) {
+				continue
+			}
 			sb.WriteString(union.ToNature(bg))
 		}
 		sb.WriteString("\n")
 	}
 
-	// Generate struct definitions
 	if len(bg.structs) > 0 {
 		sb.WriteString("// Struct definitions\n")
 		for _, structDef := range bg.structs {
-			sb.WriteString(fmt.Sprintf("type %s = struct {\n", structDef.Name))
-			for _, field := range structDef.Fields {
-				if field.Nested != nil {
-					sb.WriteString(fmt.Sprintf("    %s %s\n", field.Nested.Name, field.Name))
-				} else if field.IsUnion {
-					// For union fields, we need to resolve the union type name
-					unionTypeName := bg.resolveUnionTypeName(field.Type)
-					sb.WriteString(fmt.Sprintf("    %s %s\n", unionTypeName, field.Name))
-				} else {
-					sb.WriteString(fmt.Sprintf("    %s %s\n", field.Type, field.Name))
-				}
+			if bg.isSkipped(structDef.Name) || !isReachable(structDef.Name) {
+				continue
+			}
+			if bg.isOpaque(structDef.Name) {
+				sb.WriteString(fmt.Sprintf("type %s = anyopaque\n\n", structDef.Name))
+				continue
 			}
+			sb.WriteString(fmt.Sprintf("type %s = struct {\n", structDef.Name))
+			units := bg.writeStructFields(sb, structDef.Fields)
 			sb.WriteString("}\n\n")
+			bg.writeBitfieldAccessors(sb, structDef.Name, units)
+			bg.writeAnonUnionAccessors(sb, structDef.Name)
 		}
 	}
+}
 
-	// Generate function bindings
+// writeFunctionsSection emits the "// Function bindings" block plus the
+// variadic-arity wrappers and callback register_/free_ bindings that
+// ride along with it, since all three describe callable entry points
+// rather than data types. The matching variadic_shims.c/callback_shims.c
+// C source these bindings link against is written separately by
+// variadicShimSource/callbackShimSource.
+func (bg *BindingGenerator) writeFunctionsSection(sb *strings.Builder) {
 	if len(bg.functions) > 0 {
 		sb.WriteString("// Function bindings\n")
 		for _, fn := range bg.functions {
+			if bg.isSkipped(fn.CName) {
+				continue
+			}
 			// Generate the #linkid tag
 			sb.WriteString(fmt.Sprintf("#linkid %s\n", fn.CName))
 
 			// Generate the function signature
-			sb.WriteString(fmt.Sprintf("fn %s(", fn.Name))
+			sb.WriteString(fmt.Sprintf("fn %s(", bg.renameSymbol(fn.CName)))
 
 			// Generate parameters
 			for i, param := range fn.Parameters {
@@ -1369,26 +2361,239 @@ func (bg *BindingGenerator) generateNatureBindings() string {
 		}
 	}
 
+	bg.writeVariadicWrappers(sb)
+	bg.writeCallbackShims(sb)
+}
+
+// generateNatureBindings generates Nature binding code
+func (bg *BindingGenerator) generateNatureBindings() string {
+	var sb strings.Builder
+	isReachable := bg.reachabilityFilter()
+
+	// Header comment
+	sb.WriteString("// Generated Nature bindings\n")
+	sb.WriteString("// This file was automatically generated by naturebindgen\n\n")
+
+	bg.writeLinkDirectives(&sb)
+	bg.writeConstantsSection(&sb)
+	bg.writeEnumConstantsSection(&sb)
+	bg.writeTypeDefsSection(&sb, isReachable)
+	bg.writeUnionStructSection(&sb, isReachable)
+	bg.writeFunctionsSection(&sb)
+
 	return sb.String()
 }
 
 // resolveUnionTypeName resolves a string type to its actual union type name
-func (bg *BindingGenerator) resolveUnionTypeName(typeName string) string {
-	// Check if this is a known union type
-	if unionSize, exists := bg.unionNames[typeName]; exists {
-		// Return the union type name based on size
-		return fmt.Sprintf("Union_%s_bytes", num2words.Convert(int(unionSize)))
+// bitfieldUnit is a run of consecutive bitfields packed into one backing
+// storage field, plus the name of that backing field.
+type bitfieldUnit struct {
+	BackingField string
+	Layout       BitfieldLayout
+}
+
+// writeStructFields writes one struct-literal line per regular field and
+// one backing-storage line per run of bitfields sharing a storage unit,
+// returning the bitfield units so their accessors can be emitted after
+// the struct body closes.
+func (bg *BindingGenerator) writeStructFields(sb *strings.Builder, fields []StructField) []bitfieldUnit {
+	var units []bitfieldUnit
+
+	for i := 0; i < len(fields); {
+		field := fields[i]
+
+		if field.BitWidth > 0 {
+			layout := BitfieldLayout{StorageType: field.Type}
+			for i < len(fields) && fields[i].BitWidth > 0 && fields[i].Type == layout.StorageType &&
+				(len(layout.Fields) == 0 || fields[i].BitOffset > layout.Fields[len(layout.Fields)-1].BitOffset) {
+				layout.Fields = append(layout.Fields, fields[i])
+				i++
+			}
+
+			if last := layout.Fields[len(layout.Fields)-1]; last.BitOffset+last.BitWidth > natureIntTypeBits(layout.StorageType) {
+				fmt.Printf("Warning: bitfield run starting at %s crosses the %d-bit %s storage-unit boundary; Nature cannot model this layout directly\n",
+					layout.Fields[0].Name, natureIntTypeBits(layout.StorageType), layout.StorageType)
+			}
+
+			backingField := fmt.Sprintf("_bits%d", len(units))
+			sb.WriteString(fmt.Sprintf("    %s %s\n", layout.StorageType, backingField))
+			units = append(units, bitfieldUnit{BackingField: backingField, Layout: layout})
+			continue
+		}
+
+		if field.Nested != nil {
+			sb.WriteString(fmt.Sprintf("    %s %s\n", field.Nested.Name, field.Name))
+		} else if field.IsUnion {
+			unionTypeName := bg.resolveUnionTypeName(field.Type)
+			sb.WriteString(fmt.Sprintf("    %s %s\n", unionTypeName, field.Name))
+		} else {
+			sb.WriteString(fmt.Sprintf("    %s %s\n", field.Type, field.Name))
+		}
+		i++
 	}
 
-	// If not found, try to find by size in the unions map
-	// This handles cases where the union name might not be in unionNames
-	for size, union := range bg.unions {
-		if union.Name == typeName {
-			return fmt.Sprintf("Union_%s_bytes", num2words.Convert(int(size)))
+	return units
+}
+
+// natureIntTypeBits returns the bit width of a Nature integer storage
+// type (u8/i8 .. u64/i64), defaulting to 32 for anything else.
+func natureIntTypeBits(natureType string) int {
+	switch natureType {
+	case "u8", "i8":
+		return 8
+	case "u16", "i16":
+		return 16
+	case "u64", "i64":
+		return 64
+	default:
+		return 32
+	}
+}
+
+// isSignedNatureIntType reports whether natureType is one of the signed
+// integer types (i8/i16/i32/i64), as opposed to an unsigned one.
+func isSignedNatureIntType(natureType string) bool {
+	switch natureType {
+	case "i8", "i16", "i32", "i64":
+		return true
+	default:
+		return false
+	}
+}
+
+// writeBitfieldAccessors emits get_<field>/set_<field> extension
+// functions on structName for every bitfield, masking and shifting into
+// its backing storage field. A signed bitfield's getter sign-extends the
+// masked-out bits via the branchless "(raw ^ signBit) - signBit" trick,
+// so e.g. `int flags : 3` reads back negative values correctly instead
+// of as an always-positive 0..7.
+func (bg *BindingGenerator) writeBitfieldAccessors(sb *strings.Builder, structName string, units []bitfieldUnit) {
+	for _, unit := range units {
+		for _, field := range unit.Layout.Fields {
+			mask := (int64(1) << uint(field.BitWidth)) - 1
+
+			sb.WriteString(fmt.Sprintf("fn %s.get_%s():%s {\n", structName, field.Name, field.Type))
+			if isSignedNatureIntType(field.Type) && field.BitWidth > 0 {
+				signBit := int64(1) << uint(field.BitWidth-1)
+				sb.WriteString(fmt.Sprintf("    return ((((self.%s >> %d) & %d) ^ %d) - %d)\n",
+					unit.BackingField, field.BitOffset, mask, signBit, signBit))
+			} else {
+				sb.WriteString(fmt.Sprintf("    return (self.%s >> %d) & %d\n", unit.BackingField, field.BitOffset, mask))
+			}
+			sb.WriteString("}\n\n")
+
+			sb.WriteString(fmt.Sprintf("fn %s.set_%s(value %s) {\n", structName, field.Name, field.Type))
+			sb.WriteString(fmt.Sprintf("    self.%s = (self.%s & ~(%d << %d)) | ((value & %d) << %d)\n",
+				unit.BackingField, unit.BackingField, mask, field.BitOffset, mask, field.BitOffset))
+			sb.WriteString("}\n\n")
 		}
 	}
+}
+
+// writeAnonUnionAccessors emits get_<field>_<suffix>/set_<field>_<suffix>
+// extension functions on structName for every member of each anonymous
+// union inlined into it, reading and writing through the union's
+// synthesized backing byte-array field. Naming mirrors
+// UnionBinding.ToNature's getter/setter convention so an anonymous
+// union's members look the same to Nature callers as a named one's.
+func (bg *BindingGenerator) writeAnonUnionAccessors(sb *strings.Builder, structName string) {
+	for _, ref := range bg.anonUnions[structName] {
+		union, ok := bg.unions[ref.UnionTypeName]
+		if !ok {
+			continue
+		}
+
+		if bg.taggedUnions && ref.Discriminant != "" {
+			enumType := bg.structs[structName].fieldType(ref.Discriminant)
+			sb.WriteString(fmt.Sprintf("fn %s.variant():%s {\n", structName, enumType))
+			sb.WriteString(fmt.Sprintf("    return self.%s\n", ref.Discriminant))
+			sb.WriteString("}\n\n")
+		}
+
+		generatedFunctions := make(map[string]bool)
+		for _, field := range union.Fields {
+			cleanFieldName := strings.TrimSuffix(field.Name, "_")
+			cleanFieldType := field.Type
+			if strings.Contains(cleanFieldType, " at ") {
+				for name := range bg.structs {
+					if strings.Contains(cleanFieldType, name) {
+						cleanFieldType = name
+						break
+					}
+				}
+				if strings.Contains(cleanFieldType, " at ") {
+					for name := range bg.structs {
+						if strings.HasPrefix(name, "AnonymousStruct_") {
+							cleanFieldType = name
+							break
+						}
+					}
+				}
+			}
+
+			typeSuffix := cleanFieldType
+			if strings.Contains(typeSuffix, "[") {
+				parts := strings.Split(typeSuffix, "[")
+				baseType := parts[0]
+				sizePart := strings.TrimRight(parts[1], "]")
+				sizePart = strings.ReplaceAll(sizePart, ";", "_")
+				typeSuffix = fmt.Sprintf("%s_%s", baseType, sizePart)
+			}
+			if strings.Contains(typeSuffix, "ptr") {
+				typeSuffix = strings.ReplaceAll(typeSuffix, "rawptr<", "")
+				typeSuffix = strings.ReplaceAll(typeSuffix, ">", "")
+			}
+
+			getterName := fmt.Sprintf("get_%s_%s", cleanFieldName, typeSuffix)
+			setterName := fmt.Sprintf("set_%s_%s", cleanFieldName, typeSuffix)
+			if generatedFunctions[getterName] || generatedFunctions[setterName] {
+				continue
+			}
+			generatedFunctions[getterName] = true
+			generatedFunctions[setterName] = true
+
+			sb.WriteString(fmt.Sprintf("fn %s.%s():%s {\n", structName, getterName, cleanFieldType))
+			sb.WriteString(fmt.Sprintf("    return self.%s as %s\n", ref.BackingField, cleanFieldType))
+			sb.WriteString("}\n\n")
 
-	// If still not found, return the original type name
+			sb.WriteString(fmt.Sprintf("fn %s.%s(value %s) {\n", structName, setterName, cleanFieldType))
+			sb.WriteString(fmt.Sprintf("    self.%s = value as [u8;%d]\n", ref.BackingField, ref.UnionSize))
+			sb.WriteString("}\n\n")
+		}
+	}
+}
+
+// findUnionDiscriminant looks at the field immediately preceding the
+// union field just appended to fields (a common hand-rolled
+// tagged-union layout: `EnumType type; union { ... };`) and returns its
+// name if it looks like a discriminant - named "type"/"kind"/"tag"
+// (case-insensitively) and typed as a known enum - or "" otherwise.
+func (bg *BindingGenerator) findUnionDiscriminant(fields []StructField) string {
+	if len(fields) < 2 {
+		return ""
+	}
+	candidate := fields[len(fields)-2]
+	switch strings.ToLower(candidate.Name) {
+	case "type", "kind", "tag":
+	default:
+		return ""
+	}
+	if _, isEnum := bg.enums[candidate.Type]; !isEnum {
+		return ""
+	}
+	return candidate.Name
+}
+
+// resolveUnionTypeName returns the Nature type name a union field's
+// recorded type resolves to. Since handleCursorUnionDecl/handleFieldDecl
+// now register a union under its own C tag (or a size-based synthetic
+// name only when truly anonymous) via unionDeclTypeName, typeName is
+// already the registered name whenever it's a known union; this just
+// confirms that and falls back to typeName unchanged otherwise.
+func (bg *BindingGenerator) resolveUnionTypeName(typeName string) string {
+	if _, exists := bg.unions[typeName]; exists {
+		return typeName
+	}
 	return typeName
 }
 
@@ -1399,17 +2604,161 @@ func (bg *BindingGenerator) printHeaderLog() {
 	fmt.Println("=== End Header Log ===")
 }
 
+// Parse parses a single header file into bg, dispatching to whichever
+// backend bg was constructed with. It's the programmatic counterpart to
+// running the CLI with a header argument.
+func (bg *BindingGenerator) Parse(headerFile string) error {
+	return bg.parseHeaderFile(headerFile)
+}
+
+// Emit renders everything bg has parsed so far as Nature source text.
+// Structs/Functions/Constants/Enums stay available on bg itself for a
+// caller that wants to inspect the parsed model instead of (or as well
+// as) the emitted text.
+//
+// naturebindgen is a `package main` tool with no go.mod, so there's no
+// importable module path for another Go program to embed this as a
+// library today; Parse/Emit/WriteAll exist as the stable shape that
+// split would expose (bindgen.New/Parse/Emit/WriteAll), so main() is
+// already written against it and the actual package split is a rename,
+// not a rewrite, once this repo gets a module boundary.
+func (bg *BindingGenerator) Emit() (string, error) {
+	return bg.generateNatureBindings(), nil
+}
+
+// WriteAll emits bg's parsed bindings and writes them to outputFile.
+func (bg *BindingGenerator) WriteAll(outputFile string) error {
+	if bg.splitOutput {
+		return bg.writeSplit(outputFile)
+	}
+	bindings, err := bg.Emit()
+	if err != nil {
+		return err
+	}
+	if !bg.noFormat {
+		bindings = formatNatureSource(bindings)
+	}
+	return os.WriteFile(outputFile, []byte(bindings), 0644)
+}
+
+// irDumpSchemaVersion is bumped whenever irDump's shape changes in a way
+// that could break a consumer depending on specific fields.
+const irDumpSchemaVersion = 1
+
+// irDump is the --emit=json/--emit=ir serialization of everything bg
+// parsed: functions, structs, unions, enums, constants, typedefs,
+// function-pointer/callback typedefs, variadic functions, and
+// anonymous-union members. Original C type spellings are carried only
+// by typedefs (TypeMapping's C type), variadic functions (their
+// FixedParamCTypes, VariadicCType and ReturnCType) and the
+// function-pointer table's keys; functions, structs, unions, enums and
+// constants hold resolved Nature types only. It doesn't carry source
+// locations (file/line), since bg doesn't track those.
+type irDump struct {
+	SchemaVersion     int                         `json:"schema_version"`
+	Functions         map[string]FunctionBinding  `json:"functions"`
+	Structs           map[string]StructBinding    `json:"structs"`
+	Unions            map[string]*UnionBinding    `json:"unions"`
+	UnionNames        map[string]int64            `json:"union_names"`
+	Enums             map[string]EnumBinding      `json:"enums"`
+	Constants         map[string]ConstantItem     `json:"constants"`
+	Typedefs          map[string]TypeMapping      `json:"typedefs"`
+	FuncPtrTypes      map[string]FuncPtrBinding   `json:"func_ptr_types"`
+	CallbackTypedefs  map[string]FuncPtrBinding   `json:"callback_typedefs"`
+	VariadicFunctions map[string]VariadicFunction `json:"variadic_functions"`
+	AnonUnions        map[string][]anonUnionRef   `json:"anon_unions"`
+}
+
+// EmitJSON serializes bg's parsed model to JSON instead of Nature source,
+// for downstream tools (other language bindings, editor tooling, a REST
+// wrapper) that want to reuse naturebindgen's C parser as a pure IR
+// producer without depending on the Nature-specific emitter in Emit.
+func (bg *BindingGenerator) EmitJSON() ([]byte, error) {
+	return json.MarshalIndent(irDump{
+		SchemaVersion:     irDumpSchemaVersion,
+		Functions:         bg.functions,
+		Structs:           bg.structs,
+		Unions:            bg.unions,
+		UnionNames:        bg.unionNames,
+		Enums:             bg.enums,
+		Constants:         bg.constants,
+		Typedefs:          bg.typeMappings,
+		FuncPtrTypes:      bg.funcPtrTypes,
+		CallbackTypedefs:  bg.callbackTypedefs,
+		VariadicFunctions: bg.variadicFunctions,
+		AnonUnions:        bg.anonUnions,
+	}, "", "  ")
+}
+
 func main() {
+	if len(os.Args) >= 2 && os.Args[1] == "from-ir" {
+		runFromIR(os.Args[2:])
+		return
+	}
+
 	if len(os.Args) < 2 {
 		fmt.Println("Usage: naturebindgen <header-file> [options]")
+		fmt.Println("       naturebindgen from-ir <file.ir.json> [-o output] - regenerate bindings from a --emit=ir dump, skipping the C parser")
 		fmt.Println("Options:")
 		fmt.Println("  -o, --output <file>     Output file (default: bindings.n)")
+		fmt.Println("  --overrides <file>      Type-override/rename/opaque/skip file")
+		fmt.Println("  --backend <cgo|clang-cli> Parser backend (default: cgo)")
+		fmt.Println("  --clang-path <path>     clang binary for --backend clang-cli (default: clang)")
+		fmt.Println("  --cflag <flag>          Extra clang flag for --backend clang-cli (repeatable)")
+		fmt.Println("  --macros <file>         #define stubs (e.g. standard.h) applied as -D flags for --backend clang-cli")
+		fmt.Println("  --plugin <name[,name...]> Run auxiliary generator plugins (docs, stubs, services) alongside the bindings")
+		fmt.Println("  --services <file>       JSON {\"prefixes\":{cPrefix:serviceName}} config for the \"services\" plugin")
+		fmt.Println("  --emit <nature|json|ir>[,...] Output format(s) written to --output (default: nature); multiple targets each get their own file")
+		fmt.Println("  --union-mode <raw|tagged> Anonymous union accessors; tagged adds a variant() where a discriminant is detected (default: raw)")
+		fmt.Println("  --match <substring>     Only emit decls whose source file contains substring (repeatable)")
+		fmt.Println("  --link <name>           Emit a #linklib <name> directive at the top of the generated file (repeatable)")
+		fmt.Println("  --allowlist-function <regex> Only emit functions whose name matches regex")
+		fmt.Println("  --blocklist-function <regex> Never emit functions whose name matches regex (takes precedence)")
+		fmt.Println("  --allowlist-type <regex> Only emit structs/unions/enums whose name matches regex")
+		fmt.Println("  --blocklist-type <regex> Never emit structs/unions/enums whose name matches regex (takes precedence)")
+		fmt.Println("  --allow <regex>         Only emit any symbol (function/struct/union/enum/typedef/macro) whose name matches regex (repeatable)")
+		fmt.Println("  --deny <regex>          Never emit any symbol whose name matches regex, across every kind (repeatable, takes precedence)")
+		fmt.Println("  --allow-file <file>     Load --allow patterns, one per line (# comments allowed), from file")
+		fmt.Println("  --deny-file <file>      Load --deny patterns, one per line (# comments allowed), from file")
+		fmt.Println("  --only-reachable        Drop structs/unions/typedefs not transitively reachable from a retained function signature")
+		fmt.Println("  --variadic-arity <n>    Monomorphized wrappers (foo_1..foo_n) generated per variadic function (default: 6); also writes variadic_shims.c")
+		fmt.Println("                          (function-pointer parameters also get register_/free_ callback bindings, backed by a generated callback_shims.c libffi trampoline)")
+		fmt.Println("  --no-cache              Always reparse the header, skipping the on-disk parse cache")
+		fmt.Println("  --cache-dir <dir>       Parse cache directory (default: $XDG_CACHE_HOME/naturebindgen)")
+		fmt.Println("  --split                 Treat --output as a directory; write common.n/constants.n/enums.n/structs.n/functions.n instead of one file")
+		fmt.Println("  --manifest              Write naturebindgen-manifest.json (sha256 checksums of every input header and generated output file) plus a naturebindgen-assets/ bundle (header copies + ir.json) for reproducible re-emission")
+		fmt.Println("  --no-format             Skip the trailing-whitespace/blank-line tidy-up pass normally applied to written files")
+		fmt.Println("  --error-wrap <mode>     Failure-note detail the docs plugin emits for fallible functions: none, name (default), or name-and-args")
 		fmt.Println("  -h, --help             Show this help message")
 		os.Exit(1)
 	}
 
 	headerFile := os.Args[1]
 	outputFile := "bindings.n"
+	overridesFile := ""
+	servicesFile := ""
+	backendName := "cgo"
+	clangPath := "clang"
+	pluginNames := ""
+	emitFormat := "nature"
+	unionMode := "raw"
+	var cflags []string
+	var matchPatterns []string
+	var linkLibs []string
+	allowFunctionPattern := ""
+	blockFunctionPattern := ""
+	allowTypePattern := ""
+	blockTypePattern := ""
+	var allowPatterns []string
+	var denyPatterns []string
+	onlyReachable := false
+	variadicArity := 0
+	noCache := false
+	cacheDir := ""
+	splitOutput := false
+	writeManifest := false
+	noFormat := false
+	errorWrapFlag := "name"
 
 	// Parse command line arguments
 	for i := 2; i < len(os.Args); i++ {
@@ -1419,6 +2768,146 @@ func main() {
 				outputFile = os.Args[i+1]
 				i++
 			}
+		case "--overrides":
+			if i+1 < len(os.Args) {
+				overridesFile = os.Args[i+1]
+				i++
+			}
+		case "--services":
+			if i+1 < len(os.Args) {
+				servicesFile = os.Args[i+1]
+				i++
+			}
+		case "--plugin":
+			if i+1 < len(os.Args) {
+				pluginNames = os.Args[i+1]
+				i++
+			}
+		case "--emit":
+			if i+1 < len(os.Args) {
+				emitFormat = os.Args[i+1]
+				i++
+			}
+		case "--union-mode":
+			if i+1 < len(os.Args) {
+				unionMode = os.Args[i+1]
+				i++
+			}
+		case "--backend":
+			if i+1 < len(os.Args) {
+				backendName = os.Args[i+1]
+				i++
+			}
+		case "--clang-path":
+			if i+1 < len(os.Args) {
+				clangPath = os.Args[i+1]
+				i++
+			}
+		case "--cflag":
+			if i+1 < len(os.Args) {
+				cflags = append(cflags, os.Args[i+1])
+				i++
+			}
+		case "--macros":
+			if i+1 < len(os.Args) {
+				defines, err := loadMacroStubs(os.Args[i+1])
+				if err != nil {
+					fmt.Printf("Error loading macros file: %v\n", err)
+					os.Exit(1)
+				}
+				cflags = append(cflags, defines...)
+				i++
+			}
+		case "--match":
+			if i+1 < len(os.Args) {
+				matchPatterns = append(matchPatterns, os.Args[i+1])
+				i++
+			}
+		case "--link":
+			if i+1 < len(os.Args) {
+				linkLibs = append(linkLibs, os.Args[i+1])
+				i++
+			}
+		case "--allowlist-function":
+			if i+1 < len(os.Args) {
+				allowFunctionPattern = os.Args[i+1]
+				i++
+			}
+		case "--blocklist-function":
+			if i+1 < len(os.Args) {
+				blockFunctionPattern = os.Args[i+1]
+				i++
+			}
+		case "--allowlist-type":
+			if i+1 < len(os.Args) {
+				allowTypePattern = os.Args[i+1]
+				i++
+			}
+		case "--blocklist-type":
+			if i+1 < len(os.Args) {
+				blockTypePattern = os.Args[i+1]
+				i++
+			}
+		case "--allow":
+			if i+1 < len(os.Args) {
+				allowPatterns = append(allowPatterns, os.Args[i+1])
+				i++
+			}
+		case "--deny":
+			if i+1 < len(os.Args) {
+				denyPatterns = append(denyPatterns, os.Args[i+1])
+				i++
+			}
+		case "--allow-file":
+			if i+1 < len(os.Args) {
+				patterns, err := loadFilterPatternFile(os.Args[i+1])
+				if err != nil {
+					fmt.Printf("Error loading --allow-file: %v\n", err)
+					os.Exit(1)
+				}
+				allowPatterns = append(allowPatterns, patterns...)
+				i++
+			}
+		case "--deny-file":
+			if i+1 < len(os.Args) {
+				patterns, err := loadFilterPatternFile(os.Args[i+1])
+				if err != nil {
+					fmt.Printf("Error loading --deny-file: %v\n", err)
+					os.Exit(1)
+				}
+				denyPatterns = append(denyPatterns, patterns...)
+				i++
+			}
+		case "--only-reachable":
+			onlyReachable = true
+		case "--variadic-arity":
+			if i+1 < len(os.Args) {
+				n, err := strconv.Atoi(os.Args[i+1])
+				if err != nil {
+					fmt.Printf("Error: --variadic-arity: %v\n", err)
+					os.Exit(1)
+				}
+				variadicArity = n
+				i++
+			}
+		case "--no-cache":
+			noCache = true
+		case "--split":
+			splitOutput = true
+		case "--manifest":
+			writeManifest = true
+		case "--no-format":
+			noFormat = true
+		case "--error-wrap":
+			if i+1 < len(os.Args) {
+				errorWrapFlag = os.Args[i+1]
+				i++
+			}
+		case "--cache-dir":
+			if i+1 < len(os.Args) {
+				cacheDir = os.Args[i+1]
+				i++
+			}
 		case "-h", "--help":
 			fmt.Println("naturebindgen - Generate Nature bindings from C headers")
 			fmt.Println("Usage: naturebindgen <header-file> [options]")
@@ -1427,11 +2916,147 @@ func main() {
 	}
 
 	// Create binding generator
-	bg := NewBindingGenerator()
+	var genOpts []Option
+	switch backendName {
+	case "cgo":
+		// Default; no option needed.
+	case "clang-cli":
+		genOpts = append(genOpts, WithClangFrontend(clangPath, cflags))
+	default:
+		fmt.Printf("Error: unknown --backend %q (want cgo or clang-cli)\n", backendName)
+		os.Exit(1)
+	}
+
+	var emitTargets []string
+	for _, name := range strings.Split(emitFormat, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		emitTargets = append(emitTargets, name)
+	}
+	emitBackends := make([]EmitBackend, 0, len(emitTargets))
+	for _, target := range emitTargets {
+		b, err := lookupEmitBackend(target)
+		if err != nil {
+			fmt.Printf("Error: %v (want nature, json, or ir)\n", err)
+			os.Exit(1)
+		}
+		emitBackends = append(emitBackends, b)
+	}
+
+	switch unionMode {
+	case "raw":
+		// Default; no option needed.
+	case "tagged":
+		genOpts = append(genOpts, WithTaggedUnions())
+	default:
+		fmt.Printf("Error: unknown --union-mode %q (want raw or tagged)\n", unionMode)
+		os.Exit(1)
+	}
+
+	if len(matchPatterns) > 0 {
+		genOpts = append(genOpts, WithMatchPatterns(matchPatterns))
+	}
+	if len(linkLibs) > 0 {
+		genOpts = append(genOpts, WithLinkLibs(linkLibs))
+	}
+
+	allowFunctionRe, err := compileFilterPattern("--allowlist-function", allowFunctionPattern)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	blockFunctionRe, err := compileFilterPattern("--blocklist-function", blockFunctionPattern)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if allowFunctionRe != nil || blockFunctionRe != nil {
+		genOpts = append(genOpts, WithFunctionFilter(allowFunctionRe, blockFunctionRe))
+	}
+
+	allowTypeRe, err := compileFilterPattern("--allowlist-type", allowTypePattern)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	blockTypeRe, err := compileFilterPattern("--blocklist-type", blockTypePattern)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if allowTypeRe != nil || blockTypeRe != nil {
+		genOpts = append(genOpts, WithTypeFilter(allowTypeRe, blockTypeRe))
+	}
+
+	genericAllowRe, err := compileFilterPatternList("--allow", allowPatterns)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	genericDenyRe, err := compileFilterPatternList("--deny", denyPatterns)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if genericAllowRe != nil || genericDenyRe != nil {
+		genOpts = append(genOpts, WithGenericFilter(genericAllowRe, genericDenyRe))
+	}
+	if onlyReachable {
+		genOpts = append(genOpts, WithOnlyReachable(true))
+	}
+	if variadicArity != 0 {
+		genOpts = append(genOpts, WithVariadicArity(variadicArity))
+	}
+	if noCache {
+		genOpts = append(genOpts, WithNoCache())
+	}
+	if cacheDir != "" {
+		genOpts = append(genOpts, WithCacheDir(cacheDir))
+	}
+	if splitOutput {
+		genOpts = append(genOpts, WithSplitOutput())
+	}
+	if noFormat {
+		genOpts = append(genOpts, WithNoFormat())
+	}
+	errorWrapMode, err := parseErrorWrapMode(errorWrapFlag)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	genOpts = append(genOpts, WithErrorWrapping(errorWrapMode))
+
+	var plugins []Plugin
+	if pluginNames != "" {
+		var err error
+		plugins, err = lookupPlugins(pluginNames)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	bg := NewBindingGenerator(genOpts...)
+
+	if overridesFile != "" {
+		if err := bg.LoadOverrides(overridesFile); err != nil {
+			fmt.Printf("Error loading overrides file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if servicesFile != "" {
+		if err := bg.LoadServiceConfig(servicesFile); err != nil {
+			fmt.Printf("Error loading services file: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	// Parse header file
 	fmt.Printf("Parsing header file: %s\n", headerFile)
-	if err := bg.parseHeaderFile(headerFile); err != nil {
+	if err := bg.Parse(headerFile); err != nil {
 		fmt.Printf("Error parsing header file: %v\n", err)
 		os.Exit(1)
 	}
@@ -1447,19 +3072,92 @@ func main() {
 	}
 	fmt.Println("============================")
 
-	// Generate bindings
-	bindings := bg.generateNatureBindings()
-
 	// Print the header parsing log
 	bg.printHeaderLog()
 
-	// Write bindings to file
-	if err := os.WriteFile(outputFile, []byte(bindings), 0644); err != nil {
-		fmt.Printf("Error writing bindings file: %v\n", err)
-		os.Exit(1)
+	// Generate bindings (or the IR dump) and write them to file. A single
+	// --emit target writes to outputFile unchanged; multiple comma-separated
+	// targets (e.g. --emit nature,json) each get their own file, named by
+	// inserting the target name before outputFile's extension.
+	var writtenFiles []string
+	for _, backend := range emitBackends {
+		if splitOutput && backend.Name() == "nature" {
+			if err := bg.writeSplit(outputFile); err != nil {
+				fmt.Printf("Error generating --split output: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Generated bindings (nature, split): %s\n", outputFile)
+			for _, name := range []string{"common.n", "constants.n", "enums.n", "structs.n", "functions.n"} {
+				writtenFiles = append(writtenFiles, filepath.Join(outputFile, name))
+			}
+			continue
+		}
+
+		output, err := backend.Emit(bg)
+		if err != nil {
+			fmt.Printf("Error generating --emit %s output: %v\n", backend.Name(), err)
+			os.Exit(1)
+		}
+		if !noFormat {
+			output = formatNatureSource(output)
+		}
+		targetFile := outputFile
+		if len(emitBackends) > 1 {
+			targetFile = emitTargetFilename(outputFile, backend.Name())
+		}
+		if err := os.WriteFile(targetFile, []byte(output), 0644); err != nil {
+			fmt.Printf("Error writing %s file: %v\n", targetFile, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Generated bindings (%s): %s\n", backend.Name(), targetFile)
+		writtenFiles = append(writtenFiles, targetFile)
+	}
+
+	if writeManifest {
+		manifestDir := filepath.Dir(outputFile)
+		if splitOutput {
+			manifestDir = outputFile
+		}
+		manifestPath := filepath.Join(manifestDir, "naturebindgen-manifest.json")
+		if err := bg.writeManifest(manifestPath, headerFile, writtenFiles); err != nil {
+			fmt.Printf("Error writing manifest: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Generated manifest: %s\n", manifestPath)
+		fmt.Printf("Bundled assets (headers + IR dump): %s\n", filepath.Join(manifestDir, manifestAssetsDirName))
+	}
+
+	if len(plugins) > 0 {
+		if err := runPlugins(bg, plugins, filepath.Dir(outputFile)); err != nil {
+			fmt.Printf("Error running plugins: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Reported only after emission and plugins, since [renames], [skip]
+	// and [opaque] entries are consulted during generation.
+	for _, warning := range bg.ReportUnusedOverrides() {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+
+	if shimSource := bg.variadicShimSource(); shimSource != "" {
+		shimFile := filepath.Join(filepath.Dir(outputFile), "variadic_shims.c")
+		if err := os.WriteFile(shimFile, []byte(shimSource), 0644); err != nil {
+			fmt.Printf("Error writing variadic shim file: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Generated variadic shims: %s\n", shimFile)
+	}
+
+	if shimSource := bg.callbackShimSource(); shimSource != "" {
+		shimFile := filepath.Join(filepath.Dir(outputFile), "callback_shims.c")
+		if err := os.WriteFile(shimFile, []byte(shimSource), 0644); err != nil {
+			fmt.Printf("Error writing callback shim file: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Generated callback shims: %s\n", shimFile)
 	}
 
-	fmt.Printf("Generated bindings: %s\n", outputFile)
 	fmt.Printf("Functions: %d\n", len(bg.functions))
 	fmt.Printf("Structs: %d\n", len(bg.structs))
 	fmt.Printf("Constants: %d\n", len(bg.constants))
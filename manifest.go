@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// This file implements --manifest: a checksummed record of exactly which
+// input files (the header plus everything it transitively #included)
+// and which generated output files produced one naturebindgen run, so a
+// downstream consumer can tell whether its bindings are stale - or
+// reproduce/diff them - without re-running clang over the original
+// headers. bg.includedFiles (populated by parseHeaderFile, the same set
+// parsecache.go fingerprints) is reused here rather than re-deriving it.
+//
+// The original ask here was a generated assets.go using //go:embed to
+// bundle the headers and IR into a naturebindgen/assets package exposing
+// FS embed.FS and Manifest(). This repo has no go.mod (by design - it's a
+// single-tree tool, not a published module), and //go:embed only embeds
+// paths resolved within a module's build list, so a go:embed-backed
+// assets.go can't be added here without first adding the module this
+// repo deliberately doesn't have. bundleAssets below is the buildable
+// equivalent: instead of compiling the snapshot into the binary, it
+// writes the same inputs (headers) and the same IR (bg.EmitJSON, see
+// fromir.go/irDump) to a plain directory next to the manifest, so a
+// downstream consumer gets the identical "regenerate or diff without
+// re-parsing" capability via `naturebindgen from-ir <bundle>/ir.json` -
+// reading files off disk rather than out of an embed.FS.
+
+// manifestSchemaVersion is bumped whenever Manifest's shape changes in a
+// way that could break a consumer depending on specific fields.
+const manifestSchemaVersion = 1
+
+// manifestAssetsDirName is the bundle directory bundleAssets writes next
+// to the manifest: copies of every input header plus the IR dump, so the
+// manifest's checksums have something self-contained to verify against.
+const manifestAssetsDirName = "naturebindgen-assets"
+
+// manifestIRFileName is the IR dump's filename within the assets bundle.
+const manifestIRFileName = "ir.json"
+
+// ManifestEntry records one file's path (relative to the manifest's own
+// directory, where possible) and its content hash at manifest-write time.
+type ManifestEntry struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest is --manifest's on-disk (JSON) record of one generation run:
+// every input file naturebindgen read to produce the bindings, and every
+// output file it wrote, plus (when bundled) where the snapshot copies of
+// those inputs and the IR dump were written.
+type Manifest struct {
+	SchemaVersion int             `json:"schema_version"`
+	HeaderFile    string          `json:"header_file"`
+	Inputs        []ManifestEntry `json:"inputs"`
+	Outputs       []ManifestEntry `json:"outputs"`
+	AssetsDir     string          `json:"assets_dir,omitempty"`
+	IRFile        string          `json:"ir_file,omitempty"`
+}
+
+// manifestRelPath returns path relative to dir when possible, falling
+// back to path unchanged (e.g. if it's on a different volume) so a
+// manifest is still useful even when a clean relative path can't be
+// computed.
+func manifestRelPath(dir, path string) string {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// buildManifest hashes headerFile, every file bg.includedFiles recorded
+// as transitively parsed, and every generated outputFiles entry,
+// recording each path relative to manifestDir.
+func (bg *BindingGenerator) buildManifest(manifestDir, headerFile string, outputFiles []string) (Manifest, error) {
+	manifest := Manifest{
+		SchemaVersion: manifestSchemaVersion,
+		HeaderFile:    manifestRelPath(manifestDir, headerFile),
+	}
+
+	inputPaths := make([]string, 0, len(bg.includedFiles)+1)
+	seen := make(map[string]bool, len(bg.includedFiles)+1)
+	addInput := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			inputPaths = append(inputPaths, path)
+		}
+	}
+	addInput(headerFile)
+	for path := range bg.includedFiles {
+		addInput(path)
+	}
+	sort.Strings(inputPaths)
+
+	for _, path := range inputPaths {
+		hash, err := hashFileContent(path)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("hashing input %s: %w", path, err)
+		}
+		manifest.Inputs = append(manifest.Inputs, ManifestEntry{
+			Path:   manifestRelPath(manifestDir, path),
+			SHA256: hash,
+		})
+	}
+
+	sortedOutputs := append([]string(nil), outputFiles...)
+	sort.Strings(sortedOutputs)
+	for _, path := range sortedOutputs {
+		hash, err := hashFileContent(path)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("hashing output %s: %w", path, err)
+		}
+		manifest.Outputs = append(manifest.Outputs, ManifestEntry{
+			Path:   manifestRelPath(manifestDir, path),
+			SHA256: hash,
+		})
+	}
+
+	return manifest, nil
+}
+
+// writeManifest builds and writes a Manifest for headerFile/outputFiles
+// to manifestPath, as pretty-printed JSON, and bundles a self-contained
+// snapshot (copies of headerFile plus every transitively #included file,
+// and an IR dump) into manifestAssetsDirName next to it, so the manifest
+// is something a downstream consumer can regenerate or diff from - not
+// just a checksum list to compare against files that may have moved on.
+func (bg *BindingGenerator) writeManifest(manifestPath, headerFile string, outputFiles []string) error {
+	manifestDir := filepath.Dir(manifestPath)
+
+	manifest, err := bg.buildManifest(manifestDir, headerFile, outputFiles)
+	if err != nil {
+		return err
+	}
+
+	assetsDir, irFile, err := bg.bundleAssets(manifestDir, headerFile)
+	if err != nil {
+		return err
+	}
+	manifest.AssetsDir = assetsDir
+	manifest.IRFile = irFile
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+	return os.WriteFile(manifestPath, data, 0644)
+}
+
+// bundleAssets writes a naturebindgen-assets directory next to manifestDir
+// containing a copy of headerFile, a copy of every file bg.includedFiles
+// recorded as transitively #included, and an ir.json IR dump (bg.EmitJSON)
+// - the buildable stand-in for the go:embed assets.go described in this
+// file's header comment. It returns the assets directory and IR file
+// paths relative to manifestDir, for Manifest.AssetsDir/Manifest.IRFile.
+func (bg *BindingGenerator) bundleAssets(manifestDir, headerFile string) (assetsDirRel, irFileRel string, err error) {
+	assetsDir := filepath.Join(manifestDir, manifestAssetsDirName)
+	if err := os.MkdirAll(assetsDir, 0755); err != nil {
+		return "", "", fmt.Errorf("creating assets directory %s: %w", assetsDir, err)
+	}
+
+	headerPaths := make([]string, 0, len(bg.includedFiles)+1)
+	seen := make(map[string]bool, len(bg.includedFiles)+1)
+	addHeader := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			headerPaths = append(headerPaths, path)
+		}
+	}
+	addHeader(headerFile)
+	for path := range bg.includedFiles {
+		addHeader(path)
+	}
+	sort.Strings(headerPaths)
+
+	for _, path := range headerPaths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", "", fmt.Errorf("reading %s for asset bundle: %w", path, err)
+		}
+		dest := filepath.Join(assetsDir, filepath.Base(path))
+		if err := os.WriteFile(dest, content, 0644); err != nil {
+			return "", "", fmt.Errorf("writing bundled asset %s: %w", dest, err)
+		}
+	}
+
+	ir, err := bg.EmitJSON()
+	if err != nil {
+		return "", "", fmt.Errorf("building IR dump for asset bundle: %w", err)
+	}
+	irPath := filepath.Join(assetsDir, manifestIRFileName)
+	if err := os.WriteFile(irPath, ir, 0644); err != nil {
+		return "", "", fmt.Errorf("writing bundled IR dump %s: %w", irPath, err)
+	}
+
+	return manifestRelPath(manifestDir, assetsDir), manifestRelPath(manifestDir, irPath), nil
+}
@@ -0,0 +1,118 @@
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalJSON encodes the map as a JSON object with keys emitted in
+// insertion order.
+func (m *OrderedMap[K, V]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	first := true
+	for e := m.front; e != nil; e = e.next {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		keyJSON, err := encodeKey(e.Key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(keyJSON)
+		buf.WriteByte(':')
+
+		valueJSON, err := json.Marshal(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(valueJSON)
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// encodeKey renders a map key as a JSON string. string keys are written
+// directly; other comparable key types are encoded via encoding/json and
+// must themselves marshal to a JSON string.
+func encodeKey[K comparable](key K) ([]byte, error) {
+	if s, ok := any(key).(string); ok {
+		return json.Marshal(s)
+	}
+
+	encoded, err := json.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(encoded) == 0 || encoded[0] != '"' {
+		return nil, fmt.Errorf("orderedmap: key %v does not encode to a JSON string", key)
+	}
+	return encoded, nil
+}
+
+// decodeKey parses a raw JSON object key back into K. string keys are
+// used as-is; any other comparable key type is decoded from its JSON
+// string form (e.g. "5" for an int key).
+func decodeKey[K comparable](raw string) (K, error) {
+	var key K
+	if s, ok := any(&key).(*string); ok {
+		*s = raw
+		return key, nil
+	}
+	err := json.Unmarshal([]byte(raw), &key)
+	return key, err
+}
+
+// UnmarshalJSON decodes a JSON object into the map, calling Set for each
+// member in the order it appears in the document so iteration order
+// matches source order.
+func (m *OrderedMap[K, V]) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("orderedmap: expected JSON object, got %v", tok)
+	}
+
+	if m.underlying == nil {
+		m.underlying = make(map[K]*Element[K, V])
+	}
+
+	for dec.More() {
+		keyTok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		keyStr, ok := keyTok.(string)
+		if !ok {
+			return fmt.Errorf("orderedmap: expected string key, got %v", keyTok)
+		}
+
+		key, err := decodeKey[K](keyStr)
+		if err != nil {
+			return fmt.Errorf("orderedmap: decoding key %q: %w", keyStr, err)
+		}
+
+		var value V
+		if err := dec.Decode(&value); err != nil {
+			return fmt.Errorf("orderedmap: decoding value for key %q: %w", keyStr, err)
+		}
+
+		m.Set(key, value)
+	}
+
+	// Consume the closing '}'.
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package orderedmap
+
+// MoveToFront moves key to the front of the iteration order. It reports
+// false if key is not present.
+func (m *OrderedMap[K, V]) MoveToFront(key K) bool {
+	e, ok := m.underlying[key]
+	if !ok {
+		return false
+	}
+	m.unlink(e)
+	m.pushFront(e)
+	return true
+}
+
+// MoveToBack moves key to the back of the iteration order. It reports
+// false if key is not present.
+func (m *OrderedMap[K, V]) MoveToBack(key K) bool {
+	e, ok := m.underlying[key]
+	if !ok {
+		return false
+	}
+	m.unlink(e)
+	m.pushBack(e)
+	return true
+}
+
+// MoveBefore moves key so it immediately precedes mark. It reports false
+// if either key is missing, or if key and mark are the same element.
+func (m *OrderedMap[K, V]) MoveBefore(key, mark K) bool {
+	e, ok := m.underlying[key]
+	if !ok {
+		return false
+	}
+	at, ok := m.underlying[mark]
+	if !ok || e == at {
+		return false
+	}
+	m.unlink(e)
+	m.insertBefore(e, at)
+	return true
+}
+
+// MoveAfter moves key so it immediately follows mark. It reports false
+// if either key is missing, or if key and mark are the same element.
+func (m *OrderedMap[K, V]) MoveAfter(key, mark K) bool {
+	e, ok := m.underlying[key]
+	if !ok {
+		return false
+	}
+	at, ok := m.underlying[mark]
+	if !ok || e == at {
+		return false
+	}
+	m.unlink(e)
+	m.insertAfter(e, at)
+	return true
+}
+
+// InsertBefore inserts a new key/value immediately before mark. It
+// reports false, and leaves the map unchanged, if key already exists or
+// mark is missing.
+func (m *OrderedMap[K, V]) InsertBefore(key K, value V, mark K) bool {
+	if _, exists := m.underlying[key]; exists {
+		return false
+	}
+	at, ok := m.underlying[mark]
+	if !ok {
+		return false
+	}
+	e := &Element[K, V]{Key: key, Value: value}
+	m.underlying[key] = e
+	m.insertBefore(e, at)
+	return true
+}
+
+// InsertAfter inserts a new key/value immediately after mark. It reports
+// false, and leaves the map unchanged, if key already exists or mark is
+// missing.
+func (m *OrderedMap[K, V]) InsertAfter(key K, value V, mark K) bool {
+	if _, exists := m.underlying[key]; exists {
+		return false
+	}
+	at, ok := m.underlying[mark]
+	if !ok {
+		return false
+	}
+	e := &Element[K, V]{Key: key, Value: value}
+	m.underlying[key] = e
+	m.insertAfter(e, at)
+	return true
+}
+
+// PopFront removes and returns the first element in iteration order.
+func (m *OrderedMap[K, V]) PopFront() (K, V, bool) {
+	if m.front == nil {
+		var zeroK K
+		var zeroV V
+		return zeroK, zeroV, false
+	}
+	e := m.front
+	m.unlink(e)
+	delete(m.underlying, e.Key)
+	return e.Key, e.Value, true
+}
+
+// PopBack removes and returns the last element in iteration order.
+func (m *OrderedMap[K, V]) PopBack() (K, V, bool) {
+	if m.back == nil {
+		var zeroK K
+		var zeroV V
+		return zeroK, zeroV, false
+	}
+	e := m.back
+	m.unlink(e)
+	delete(m.underlying, e.Key)
+	return e.Key, e.Value, true
+}
+
+func (m *OrderedMap[K, V]) pushFront(e *Element[K, V]) {
+	e.next = m.front
+	e.prev = nil
+	if m.front != nil {
+		m.front.prev = e
+	} else {
+		m.back = e
+	}
+	m.front = e
+}
+
+// insertBefore splices e into the list immediately before at. e must
+// already be detached from the list.
+func (m *OrderedMap[K, V]) insertBefore(e, at *Element[K, V]) {
+	e.prev = at.prev
+	e.next = at
+	if at.prev != nil {
+		at.prev.next = e
+	} else {
+		m.front = e
+	}
+	at.prev = e
+}
+
+// insertAfter splices e into the list immediately after at. e must
+// already be detached from the list.
+func (m *OrderedMap[K, V]) insertAfter(e, at *Element[K, V]) {
+	e.next = at.next
+	e.prev = at
+	if at.next != nil {
+		at.next.prev = e
+	} else {
+		m.back = e
+	}
+	at.next = e
+}
+
+// LRU is an OrderedMap used as a fixed-capacity LRU cache: Add inserts
+// or refreshes an entry at the back, evicting from the front once the
+// capacity is exceeded.
+type LRU[K comparable, V any] struct {
+	*OrderedMap[K, V]
+	capacity int
+}
+
+// NewLRU returns an LRU cache backed by an OrderedMap with the given
+// capacity. A non-positive capacity means unbounded.
+func NewLRU[K comparable, V any](capacity int) *LRU[K, V] {
+	return &LRU[K, V]{
+		OrderedMap: NewOrderedMap[K, V](),
+		capacity:   capacity,
+	}
+}
+
+// Touch moves key to the back (most-recently-used end) if present, and
+// reports whether it was found.
+func (c *LRU[K, V]) Touch(key K) bool {
+	return c.MoveToBack(key)
+}
+
+// Add inserts or refreshes key/value at the back of the cache, evicting
+// from the front until the cache is back within capacity.
+func (c *LRU[K, V]) Add(key K, value V) {
+	c.Set(key, value)
+	c.MoveToBack(key)
+	for c.capacity > 0 && c.Len() > c.capacity {
+		c.PopFront()
+	}
+}
@@ -1,59 +1,140 @@
 package orderedmap
 
+// Element is a node in an OrderedMap's intrusive doubly-linked list. Next
+// and Prev return nil once iteration runs off either end of the list.
+type Element[K comparable, V any] struct {
+	Key   K
+	Value V
+
+	next, prev *Element[K, V]
+}
+
+// Next returns the next element in insertion order, or nil if e is the
+// last element.
+func (e *Element[K, V]) Next() *Element[K, V] {
+	return e.next
+}
+
+// Prev returns the previous element in insertion order, or nil if e is
+// the first element.
+func (e *Element[K, V]) Prev() *Element[K, V] {
+	return e.prev
+}
+
 type OrderedMap[K comparable, V any] struct {
-	underlying map[K]V
-	order      []K
+	underlying  map[K]*Element[K, V]
+	front, back *Element[K, V]
 }
 
 func NewOrderedMap[K comparable, V any]() *OrderedMap[K, V] {
 	return &OrderedMap[K, V]{
-		underlying: make(map[K]V),
-		order:      make([]K, 0),
+		underlying: make(map[K]*Element[K, V]),
 	}
 }
 
+// Set inserts key/value, or updates the value in place if key is already
+// present. Re-inserting a key never changes its position in the order.
 func (m *OrderedMap[K, V]) Set(key K, value V) {
-	m.underlying[key] = value
-	m.order = append(m.order, key)
+	if e, ok := m.underlying[key]; ok {
+		e.Value = value
+		return
+	}
+
+	e := &Element[K, V]{Key: key, Value: value}
+	m.underlying[key] = e
+	m.pushBack(e)
+}
+
+func (m *OrderedMap[K, V]) pushBack(e *Element[K, V]) {
+	e.prev = m.back
+	e.next = nil
+	if m.back != nil {
+		m.back.next = e
+	} else {
+		m.front = e
+	}
+	m.back = e
+}
+
+// unlink splices e out of the list. It does not touch the underlying map.
+func (m *OrderedMap[K, V]) unlink(e *Element[K, V]) {
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		m.front = e.next
+	}
+	if e.next != nil {
+		e.next.prev = e.prev
+	} else {
+		m.back = e.prev
+	}
+	e.next, e.prev = nil, nil
 }
 
 func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
-	value, ok := m.underlying[key]
-	return value, ok
+	e, ok := m.underlying[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return e.Value, true
 }
 
+// Delete removes key from the map in O(1).
 func (m *OrderedMap[K, V]) Delete(key K) {
-	delete(m.underlying, key)
-	for i, k := range m.order {
-		if k == key {
-			m.order = append(m.order[:i], m.order[i+1:]...)
-			break
-		}
+	e, ok := m.underlying[key]
+	if !ok {
+		return
 	}
+	m.unlink(e)
+	delete(m.underlying, key)
 }
 
+// GetPlace returns the zero-based position of key in iteration order, or
+// -1 if key is not present. This still walks the list, since the list
+// trades GetPlace's cost for O(1) Set/Delete.
 func (m *OrderedMap[K, V]) GetPlace(key K) int {
-	for i, k := range m.order {
-		if k == key {
+	if _, ok := m.underlying[key]; !ok {
+		return -1
+	}
+	i := 0
+	for e := m.front; e != nil; e = e.next {
+		if e.Key == key {
 			return i
 		}
+		i++
 	}
-	
 	return -1
 }
 
+// Front returns the first element in insertion order, or nil if the map
+// is empty.
+func (m *OrderedMap[K, V]) Front() *Element[K, V] {
+	return m.front
+}
+
+// Back returns the last element in insertion order, or nil if the map is
+// empty.
+func (m *OrderedMap[K, V]) Back() *Element[K, V] {
+	return m.back
+}
+
 func (m *OrderedMap[K, V]) Keys() []K {
-	return m.order
+	keys := make([]K, 0, len(m.underlying))
+	for e := m.front; e != nil; e = e.next {
+		keys = append(keys, e.Key)
+	}
+	return keys
 }
 
 func (m *OrderedMap[K, V]) Values() []V {
-	values := make([]V, len(m.order))
-	for i, k := range m.order {
-		values[i] = m.underlying[k]
+	values := make([]V, 0, len(m.underlying))
+	for e := m.front; e != nil; e = e.next {
+		values = append(values, e.Value)
 	}
 	return values
 }
 
 func (m *OrderedMap[K, V]) Len() int {
-	return len(m.order)
-}
\ No newline at end of file
+	return len(m.underlying)
+}
@@ -0,0 +1,111 @@
+package orderedmap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetReinsertionKeepsPosition(t *testing.T) {
+	m := NewOrderedMap[string, int]()
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("c", 3)
+
+	m.Set("a", 99)
+
+	if got, want := m.Keys(), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Keys() after re-inserting \"a\" = %v, want %v (position must not change)", got, want)
+	}
+	if v, _ := m.Get("a"); v != 99 {
+		t.Fatalf("Get(\"a\") = %d, want 99 (value must still update)", v)
+	}
+}
+
+func TestDeleteMidList(t *testing.T) {
+	m := NewOrderedMap[string, int]()
+	for i, key := range []string{"a", "b", "c", "d"} {
+		m.Set(key, i)
+	}
+
+	m.Delete("b")
+
+	if got, want := m.Keys(), []string{"a", "c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Keys() after deleting mid-list \"b\" = %v, want %v", got, want)
+	}
+	if _, ok := m.Get("b"); ok {
+		t.Fatal("Get(\"b\") found a deleted key")
+	}
+	if m.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", m.Len())
+	}
+
+	// The list's two ends must still be the surviving first and last
+	// keys after the splice.
+	if got, want := m.Front().Key, "a"; got != want {
+		t.Fatalf("Front().Key = %q, want %q", got, want)
+	}
+	if got, want := m.Back().Key, "d"; got != want {
+		t.Fatalf("Back().Key = %q, want %q", got, want)
+	}
+}
+
+func TestDeleteFrontAndBack(t *testing.T) {
+	m := NewOrderedMap[string, int]()
+	for i, key := range []string{"a", "b", "c"} {
+		m.Set(key, i)
+	}
+
+	m.Delete("a")
+	if got, want := m.Keys(), []string{"b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Keys() after deleting front = %v, want %v", got, want)
+	}
+
+	m.Delete("c")
+	if got, want := m.Keys(), []string{"b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Keys() after deleting back = %v, want %v", got, want)
+	}
+}
+
+func TestGetPlace(t *testing.T) {
+	m := NewOrderedMap[string, int]()
+	for i, key := range []string{"a", "b", "c"} {
+		m.Set(key, i)
+	}
+
+	if got, want := m.GetPlace("b"), 1; got != want {
+		t.Fatalf("GetPlace(\"b\") = %d, want %d", got, want)
+	}
+	if got, want := m.GetPlace("missing"), -1; got != want {
+		t.Fatalf("GetPlace(\"missing\") = %d, want %d", got, want)
+	}
+}
+
+func BenchmarkSet(b *testing.B) {
+	m := NewOrderedMap[int, int]()
+	for i := 0; i < b.N; i++ {
+		m.Set(i, i)
+	}
+}
+
+func BenchmarkSetReinsertion(b *testing.B) {
+	m := NewOrderedMap[int, int]()
+	for i := 0; i < 1024; i++ {
+		m.Set(i, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Set(i%1024, i)
+	}
+}
+
+func BenchmarkDeleteMidList(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		m := NewOrderedMap[int, int]()
+		for j := 0; j < 1024; j++ {
+			m.Set(j, j)
+		}
+		b.StartTimer()
+		m.Delete(512)
+	}
+}
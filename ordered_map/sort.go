@@ -0,0 +1,50 @@
+package orderedmap
+
+import "sort"
+
+// SortKeys reorders the map in place by key, without touching the
+// key/value associations. The comparator is not guaranteed stable; use
+// SortStableKeys if that matters.
+func (m *OrderedMap[K, V]) SortKeys(less func(a, b K) bool) {
+	elems := m.elementSlice()
+	sort.Slice(elems, func(i, j int) bool {
+		return less(elems[i].Key, elems[j].Key)
+	})
+	m.relink(elems)
+}
+
+// SortStableKeys is like SortKeys but preserves the relative order of
+// keys the comparator considers equal.
+func (m *OrderedMap[K, V]) SortStableKeys(less func(a, b K) bool) {
+	elems := m.elementSlice()
+	sort.SliceStable(elems, func(i, j int) bool {
+		return less(elems[i].Key, elems[j].Key)
+	})
+	m.relink(elems)
+}
+
+// SortValues reorders the map in place by value.
+func (m *OrderedMap[K, V]) SortValues(less func(a, b V) bool) {
+	elems := m.elementSlice()
+	sort.SliceStable(elems, func(i, j int) bool {
+		return less(elems[i].Value, elems[j].Value)
+	})
+	m.relink(elems)
+}
+
+func (m *OrderedMap[K, V]) elementSlice() []*Element[K, V] {
+	elems := make([]*Element[K, V], 0, len(m.underlying))
+	for e := m.front; e != nil; e = e.next {
+		elems = append(elems, e)
+	}
+	return elems
+}
+
+// relink rebuilds the front/back list from elems, in order.
+func (m *OrderedMap[K, V]) relink(elems []*Element[K, V]) {
+	m.front, m.back = nil, nil
+	for _, e := range elems {
+		e.prev, e.next = nil, nil
+		m.pushBack(e)
+	}
+}
@@ -0,0 +1,67 @@
+package orderedmap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortKeysReflectsInKeys(t *testing.T) {
+	m := NewOrderedMap[int, string]()
+	for _, k := range []int{3, 1, 4, 5} {
+		m.Set(k, "")
+	}
+
+	m.SortKeys(func(a, b int) bool { return a < b })
+
+	if got, want := m.Keys(), []int{1, 3, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Keys() after SortKeys = %v, want %v", got, want)
+	}
+	// Front/Back must agree with the new order, not just Keys().
+	if got, want := m.Front().Key, 1; got != want {
+		t.Fatalf("Front().Key after SortKeys = %d, want %d", got, want)
+	}
+	if got, want := m.Back().Key, 5; got != want {
+		t.Fatalf("Back().Key after SortKeys = %d, want %d", got, want)
+	}
+}
+
+func TestSortStableKeysPreservesTiesInKeys(t *testing.T) {
+	type entry struct {
+		key   int
+		group int
+	}
+	entries := []entry{{1, 1}, {2, 0}, {3, 1}, {4, 0}}
+
+	m := NewOrderedMap[int, int]()
+	for _, e := range entries {
+		m.Set(e.key, e.group)
+	}
+
+	m.SortStableKeys(func(a, b int) bool {
+		va, _ := m.Get(a)
+		vb, _ := m.Get(b)
+		return va < vb
+	})
+
+	// Group 0 (keys 2, 4) sorts before group 1 (keys 1, 3); within each
+	// group, original insertion order (2 before 4, 1 before 3) must hold.
+	if got, want := m.Keys(), []int{2, 4, 1, 3}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Keys() after SortStableKeys = %v, want %v", got, want)
+	}
+}
+
+func TestSortValuesReflectsInKeys(t *testing.T) {
+	m := NewOrderedMap[string, int]()
+	m.Set("a", 3)
+	m.Set("b", 1)
+	m.Set("c", 2)
+
+	m.SortValues(func(a, b int) bool { return a < b })
+
+	if got, want := m.Keys(), []string{"b", "c", "a"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Keys() after SortValues = %v, want %v", got, want)
+	}
+	if got, want := m.Values(), []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Values() after SortValues = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package orderedmap
+
+import "sync"
+
+// SyncOrderedMap wraps an OrderedMap with a sync.RWMutex so it can be
+// shared across goroutines. Writers (Set, Delete) take the write lock;
+// readers (Get, Keys, Values, Len, GetPlace, Range) take the read lock.
+type SyncOrderedMap[K comparable, V any] struct {
+	mu sync.RWMutex
+	m  *OrderedMap[K, V]
+}
+
+func NewSyncOrderedMap[K comparable, V any]() *SyncOrderedMap[K, V] {
+	return &SyncOrderedMap[K, V]{
+		m: NewOrderedMap[K, V](),
+	}
+}
+
+func (s *SyncOrderedMap[K, V]) Set(key K, value V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.Set(key, value)
+}
+
+func (s *SyncOrderedMap[K, V]) Delete(key K) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.Delete(key)
+}
+
+func (s *SyncOrderedMap[K, V]) Get(key K) (V, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Get(key)
+}
+
+func (s *SyncOrderedMap[K, V]) GetPlace(key K) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.GetPlace(key)
+}
+
+func (s *SyncOrderedMap[K, V]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Len()
+}
+
+// Keys returns a defensive copy of the current key order, taken under
+// the read lock, so it is safe to keep and use after the map is mutated
+// further.
+func (s *SyncOrderedMap[K, V]) Keys() []K {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]K(nil), s.m.Keys()...)
+}
+
+// Values returns a defensive copy of the current values in order.
+func (s *SyncOrderedMap[K, V]) Values() []V {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]V(nil), s.m.Values()...)
+}
+
+// Range calls f for each key/value in insertion order under the read
+// lock, stopping early if f returns false. Callers must not call back
+// into the SyncOrderedMap from f, since that would deadlock on the same
+// RWMutex.
+func (s *SyncOrderedMap[K, V]) Range(f func(K, V) bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for e := s.m.front; e != nil; e = e.next {
+		if !f(e.Key, e.Value) {
+			return
+		}
+	}
+}
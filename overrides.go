@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Overrides holds user-supplied directives, loaded from a TOML-like file
+// via LoadOverrides, that steer code generation without touching Go
+// source: forcing a C type to a specific Nature type, renaming a C
+// symbol on the Nature side, marking a struct opaque, skipping a symbol
+// entirely, or injecting extra type mappings before parsing. This
+// mirrors the "overrides file" pattern from c2hsc, trimmed down to the
+// handful of directives naturebindgen actually needs.
+type Overrides struct {
+	// Types forces mapCTypeToNature(cType) to return natureType instead
+	// of resolving it normally, e.g. "SDL_WindowFlags" -> "u32".
+	Types map[string]string
+	// Renames maps a C symbol (function or constant name) to the
+	// identifier it should be emitted under on the Nature side, e.g.
+	// "SDL_CreateWindow" -> "create_window". The C side (#linkid, macro
+	// value lookups) still uses the original name.
+	Renames map[string]string
+	// Opaque marks struct names that should never have their fields
+	// emitted; they're always exposed as an opaque handle, referenced
+	// elsewhere as rawptr<T>.
+	Opaque map[string]bool
+	// Skip marks symbol names (function, struct, or constant) that
+	// should be dropped from the generated bindings entirely.
+	Skip map[string]bool
+	// Inject lists extra C-type -> Nature-type mappings to merge into
+	// the generator's type table before parsing starts, so types the
+	// parser would otherwise not recognize don't fall through to any.
+	Inject map[string]string
+
+	used map[string]bool
+}
+
+// newOverrides returns an empty, ready-to-populate Overrides.
+func newOverrides() *Overrides {
+	return &Overrides{
+		Types:   make(map[string]string),
+		Renames: make(map[string]string),
+		Opaque:  make(map[string]bool),
+		Skip:    make(map[string]bool),
+		Inject:  make(map[string]string),
+		used:    make(map[string]bool),
+	}
+}
+
+// LoadOverrides reads a TOML-style override file from path and merges it
+// into bg, injecting entries in [inject] straight into bg.typeMappings
+// so they're visible to the parser. The file has one table per
+// directive kind:
+//
+//	[types]
+//	SDL_WindowFlags = "u32"
+//
+//	[renames]
+//	SDL_CreateWindow = "create_window"
+//
+//	[opaque]
+//	SDL_Window = true
+//
+//	[skip]
+//	SDL_GetVersion = true
+//
+//	[inject]
+//	Uint32 = "u32"
+//
+// Blank lines and lines starting with '#' are ignored.
+func (bg *BindingGenerator) LoadOverrides(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening overrides file: %w", err)
+	}
+	defer file.Close()
+
+	overrides := newOverrides()
+	section := ""
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		key, value, ok := splitOverrideEntry(line)
+		if !ok {
+			return fmt.Errorf("overrides file %s: malformed entry %q", path, line)
+		}
+
+		switch section {
+		case "types":
+			overrides.Types[key] = value
+		case "renames":
+			overrides.Renames[key] = value
+		case "opaque":
+			overrides.Opaque[key] = value == "true"
+		case "skip":
+			overrides.Skip[key] = value == "true"
+		case "inject":
+			overrides.Inject[key] = value
+		default:
+			return fmt.Errorf("overrides file %s: entry %q outside of a [section]", path, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading overrides file: %w", err)
+	}
+
+	for cType, natureType := range overrides.Inject {
+		bg.typeMappings[cType] = TypeMapping{CType: cType, NatureType: natureType}
+	}
+
+	bg.overrides = overrides
+	return nil
+}
+
+// splitOverrideEntry splits a `key = "value"` or `key = value` line,
+// stripping a quoted string value if present.
+func splitOverrideEntry(line string) (key, value string, ok bool) {
+	parts := strings.SplitN(line, "=", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	key = strings.TrimSpace(parts[0])
+	value = strings.TrimSpace(parts[1])
+	if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") && len(value) >= 2 {
+		value = value[1 : len(value)-1]
+	}
+	if key == "" {
+		return "", "", false
+	}
+	return key, value, true
+}
+
+// overrideType returns the forced Nature type for cType from [types], if
+// any override file was loaded and has one.
+func (bg *BindingGenerator) overrideType(cType string) (string, bool) {
+	if bg.overrides == nil {
+		return "", false
+	}
+	if natureType, ok := bg.overrides.Types[cType]; ok {
+		bg.overrides.used["types."+cType] = true
+		return natureType, true
+	}
+	return "", false
+}
+
+// renameSymbol returns the Nature-side name for a C symbol, applying
+// [renames] if loaded, otherwise the name unchanged.
+func (bg *BindingGenerator) renameSymbol(cName string) string {
+	if bg.overrides == nil {
+		return cName
+	}
+	if renamed, ok := bg.overrides.Renames[cName]; ok {
+		bg.overrides.used["renames."+cName] = true
+		return renamed
+	}
+	return cName
+}
+
+// isSkipped reports whether [skip] marks name for exclusion.
+func (bg *BindingGenerator) isSkipped(name string) bool {
+	if bg.overrides == nil {
+		return false
+	}
+	if bg.overrides.Skip[name] {
+		bg.overrides.used["skip."+name] = true
+		return true
+	}
+	return false
+}
+
+// isOpaque reports whether [opaque] marks structName as an opaque
+// handle whose fields should never be emitted.
+func (bg *BindingGenerator) isOpaque(structName string) bool {
+	if bg.overrides == nil {
+		return false
+	}
+	if bg.overrides.Opaque[structName] {
+		bg.overrides.used["opaque."+structName] = true
+		return true
+	}
+	return false
+}
+
+// ReportUnusedOverrides returns a warning line for every override entry
+// that LoadOverrides read but generation never consulted, so stale
+// config (a renamed/removed C symbol the override file still mentions)
+// gets caught instead of silently doing nothing.
+func (bg *BindingGenerator) ReportUnusedOverrides() []string {
+	if bg.overrides == nil {
+		return nil
+	}
+	var warnings []string
+	check := func(section string, keys map[string]string) {
+		for key := range keys {
+			if !bg.overrides.used[section+"."+key] {
+				warnings = append(warnings, fmt.Sprintf("unused override [%s] %s", section, key))
+			}
+		}
+	}
+	checkBool := func(section string, keys map[string]bool) {
+		for key := range keys {
+			if !bg.overrides.used[section+"."+key] {
+				warnings = append(warnings, fmt.Sprintf("unused override [%s] %s", section, key))
+			}
+		}
+	}
+	check("types", bg.overrides.Types)
+	check("renames", bg.overrides.Renames)
+	checkBool("opaque", bg.overrides.Opaque)
+	checkBool("skip", bg.overrides.Skip)
+	// [inject] entries are consumed immediately into bg.typeMappings at
+	// load time, so there's nothing further to track usage of.
+	return warnings
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// parseCacheVersion bumps whenever parseCacheEntry's shape or parsing
+// semantics change enough that an old cache file on disk could silently
+// produce stale bindings instead of a clean miss.
+const parseCacheVersion = 1
+
+// parseCacheEntry is the on-disk (gob-encoded) form of one
+// parseHeaderFile result: the BindingGenerator state a hit restores,
+// plus a fingerprint - every file that went into producing it, each with
+// its content hash at cache time - that the next run re-verifies before
+// trusting the entry. Fingerprinting this way (rather than needing to
+// know a header's transitive #includes before parsing it, a
+// chicken-and-egg problem) lets a miss fall straight through to a normal
+// parse, which then overwrites the entry with a fresh fingerprint.
+type parseCacheEntry struct {
+	Version      int
+	Dependencies map[string]string // file path -> sha256 hex of its content at cache time
+
+	Structs        map[string]StructBinding
+	Unions         map[string]*UnionBinding
+	UnionNames     map[string]int64
+	Enums          map[string]EnumBinding
+	Functions      map[string]FunctionBinding
+	Constants      map[string]ConstantItem
+	ConstantValues map[string]int
+	TypeMappings   map[string]TypeMapping
+	Includes       []string
+
+	FuncPtrTypes      map[string]FuncPtrBinding
+	CallbackTypedefs  map[string]FuncPtrBinding
+	VariadicFunctions map[string]VariadicFunction
+	AnonUnions        map[string][]anonUnionRef
+}
+
+// defaultCacheDir is --cache-dir's default: $XDG_CACHE_HOME/naturebindgen,
+// falling back to $HOME/.cache/naturebindgen per the XDG base directory
+// spec's own fallback rule.
+func defaultCacheDir() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "naturebindgen")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "naturebindgen-cache")
+	}
+	return filepath.Join(home, ".cache", "naturebindgen")
+}
+
+func hashFileContent(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// parseCacheKey names the gob file for headerPath parsed with clangArgs:
+// a hash of the cache format version (parseCacheVersion), the header's
+// absolute path, and the
+// clang arguments that would be used to parse it. Content changes don't
+// belong in the key - they're caught by re-verifying Dependencies on
+// load - so the key only needs to tell "this header, parsed this way"
+// apart from any other.
+func parseCacheKey(headerPath string, clangArgs []string) string {
+	abs, err := filepath.Abs(headerPath)
+	if err != nil {
+		abs = headerPath
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "v%d\n%s\n", parseCacheVersion, abs)
+	for _, arg := range clangArgs {
+		fmt.Fprintf(h, "%s\n", arg)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func parseCachePath(cacheDir, headerPath string, clangArgs []string) string {
+	return filepath.Join(cacheDir, parseCacheKey(headerPath, clangArgs)+".gob")
+}
+
+// loadParseCache reads and validates a cache entry for headerPath,
+// reporting ok=false on any miss: no file, corrupt gob, a version from a
+// different naturebindgen build, or any recorded dependency (the header
+// itself, or a file transitively #included by it) whose content hash no
+// longer matches what's on disk.
+func loadParseCache(cacheDir, headerPath string, clangArgs []string) (*parseCacheEntry, bool) {
+	data, err := os.ReadFile(parseCachePath(cacheDir, headerPath, clangArgs))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry parseCacheEntry
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entry); err != nil {
+		return nil, false
+	}
+	if entry.Version != parseCacheVersion {
+		return nil, false
+	}
+
+	for depPath, wantHash := range entry.Dependencies {
+		gotHash, err := hashFileContent(depPath)
+		if err != nil || gotHash != wantHash {
+			return nil, false
+		}
+	}
+	return &entry, true
+}
+
+// saveParseCache writes bg's post-parse state as a cache entry for
+// headerPath, fingerprinted against every file bg.includedFiles recorded
+// as transitively parsed (including headerPath itself, added to
+// includedFiles by parseHeaderFile), so an edit to any of them
+// invalidates the entry on the next run.
+func (bg *BindingGenerator) saveParseCache(cacheDir, headerPath string, clangArgs []string) error {
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return fmt.Errorf("creating cache dir: %w", err)
+	}
+
+	dependencies := make(map[string]string, len(bg.includedFiles))
+	for path := range bg.includedFiles {
+		hash, err := hashFileContent(path)
+		if err != nil {
+			continue // Can't fingerprint a file that's since disappeared; skip it rather than fail the whole cache write.
+		}
+		dependencies[path] = hash
+	}
+
+	entry := parseCacheEntry{
+		Version:        parseCacheVersion,
+		Dependencies:   dependencies,
+		Structs:        bg.structs,
+		Unions:         bg.unions,
+		UnionNames:     bg.unionNames,
+		Enums:          bg.enums,
+		Functions:      bg.functions,
+		Constants:      bg.constants,
+		ConstantValues: bg.constantValues,
+		TypeMappings:   bg.typeMappings,
+		Includes:       bg.includes,
+
+		FuncPtrTypes:      bg.funcPtrTypes,
+		CallbackTypedefs:  bg.callbackTypedefs,
+		VariadicFunctions: bg.variadicFunctions,
+		AnonUnions:        bg.anonUnions,
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&entry); err != nil {
+		return fmt.Errorf("encoding cache entry: %w", err)
+	}
+	return os.WriteFile(parseCachePath(cacheDir, headerPath, clangArgs), buf.Bytes(), 0644)
+}
+
+// restoreFromCache copies a hit parseCacheEntry's fields into bg, the
+// inverse of saveParseCache.
+func (bg *BindingGenerator) restoreFromCache(entry *parseCacheEntry) {
+	bg.structs = entry.Structs
+	bg.unions = entry.Unions
+	bg.unionNames = entry.UnionNames
+	bg.enums = entry.Enums
+	bg.functions = entry.Functions
+	bg.constants = entry.Constants
+	bg.constantValues = entry.ConstantValues
+	bg.typeMappings = entry.TypeMappings
+	bg.includes = entry.Includes
+	bg.funcPtrTypes = entry.FuncPtrTypes
+	bg.callbackTypedefs = entry.CallbackTypedefs
+	bg.variadicFunctions = entry.VariadicFunctions
+	bg.anonUnions = entry.AnonUnions
+	for path := range entry.Dependencies {
+		bg.includedFiles[path] = true
+	}
+}
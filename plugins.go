@@ -0,0 +1,283 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PluginFile is one auxiliary artifact a Plugin wants written alongside
+// the generated .n bindings.
+type PluginFile struct {
+	Name    string
+	Content string
+}
+
+// Plugin generates auxiliary artifacts from an already-parsed
+// BindingGenerator — a Markdown API reference, skeleton test stubs, or
+// whatever else a domain-specific workflow needs, without forking the
+// generator to get it.
+type Plugin interface {
+	Name() string
+	Generate(bg *BindingGenerator) ([]PluginFile, error)
+}
+
+// pluginRegistry holds every Plugin registered via RegisterPlugin,
+// keyed by Name(). Built-in plugins register themselves from init().
+var pluginRegistry = map[string]Plugin{}
+
+// RegisterPlugin makes p available to --plugin by name. Re-registering
+// an existing name replaces it.
+func RegisterPlugin(p Plugin) {
+	pluginRegistry[p.Name()] = p
+}
+
+// lookupPlugins resolves a comma-separated --plugin list to registered
+// Plugins, reporting an error naming the first unknown plugin found.
+func lookupPlugins(names string) ([]Plugin, error) {
+	var plugins []Plugin
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		p, ok := pluginRegistry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown plugin %q", name)
+		}
+		plugins = append(plugins, p)
+	}
+	return plugins, nil
+}
+
+// runPlugins runs each plugin against bg and writes its files into dir.
+func runPlugins(bg *BindingGenerator, plugins []Plugin, dir string) error {
+	for _, p := range plugins {
+		files, err := p.Generate(bg)
+		if err != nil {
+			return fmt.Errorf("plugin %s: %w", p.Name(), err)
+		}
+		for _, f := range files {
+			path := filepath.Join(dir, f.Name)
+			if err := os.WriteFile(path, []byte(f.Content), 0644); err != nil {
+				return fmt.Errorf("plugin %s: writing %s: %w", p.Name(), path, err)
+			}
+			fmt.Printf("Generated %s: %s\n", p.Name(), path)
+		}
+	}
+	return nil
+}
+
+func init() {
+	RegisterPlugin(docsPlugin{})
+	RegisterPlugin(stubsPlugin{})
+}
+
+// ErrorWrapMode selects how much failure-handling detail docsPlugin
+// surfaces for a fallible function's Markdown note, via --error-wrap.
+//
+// This is a partial, re-scoped delivery of that request, not the thing
+// it asked for, and it's written down here rather than left implicit:
+// the ask was call-site error wrapping, where every generated wrapper
+// around a fallible C function would wrap its result with
+// fmt.Errorf("<fn>() failed: %w", err), plus a checkErr() helper
+// consulting a thread-local last-error slot. Both pieces are Go idioms
+// (the go-clang/cgo wrapper layer this tool doesn't generate), and
+// neither has a home in this tool's actual output: it emits Nature
+// source only (see writeFunctionsSection) - there is no Go emission
+// path at all to carry fmt.Errorf/checkErr in - and this repo has found
+// no try/catch, Result<T>, extern-global, or errno-propagating construct
+// anywhere in verified Nature code to build an in-Nature equivalent from
+// either (see ceval.go and variadic.go's writeVariadicWrappers for the
+// same no-verified-branching-syntax constraint). Nothing here
+// implements call-site wrapping or a checkErr helper. ErrorWrapMode is
+// confined to the one piece of this that's real and buildable today:
+// how much the docs plugin's failure note tells a caller about a
+// function's C failure convention, from "don't mention it" up to
+// "mention it with the parameters a caller would need to diagnose which
+// arg failed". If the call-site wrapping is still wanted, this request
+// needs re-scoping toward a language this tool actually emits.
+type ErrorWrapMode int
+
+const (
+	// ErrorWrapName notes the failure convention by name only (the
+	// default, and BindingGenerator's zero value, so a caller that never
+	// touches --error-wrap keeps the original unconditional note) - e.g.
+	// "may return NULL on failure".
+	ErrorWrapName ErrorWrapMode = iota
+	// ErrorWrapNameAndArgs additionally lists the function's parameters,
+	// so the note reads e.g. "may return NULL on failure (path, mode)".
+	ErrorWrapNameAndArgs
+	// ErrorWrapNone omits the failure note entirely.
+	ErrorWrapNone
+)
+
+// parseErrorWrapMode parses --error-wrap's flag value ("none", "name", or
+// "name-and-args"). Empty input means ErrorWrapName; any other
+// unrecognized value returns an error.
+func parseErrorWrapMode(s string) (ErrorWrapMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "name":
+		return ErrorWrapName, nil
+	case "none":
+		return ErrorWrapNone, nil
+	case "name-and-args":
+		return ErrorWrapNameAndArgs, nil
+	default:
+		return ErrorWrapName, fmt.Errorf("--error-wrap: unknown mode %q (want none, name, or name-and-args)", s)
+	}
+}
+
+// fallibleReturnNote returns a short Markdown note on the C failure
+// convention a function's Nature return type implies, or "" if none is
+// inferable or mode is ErrorWrapNone. Only the pointer case is flagged -
+// "returns a negative/zero int on failure" is too common a false
+// positive to claim generically from the return type alone (plenty of
+// i32-returning functions just return a count or an index), whereas a
+// pointer return in C has exactly one conventional failure value: NULL.
+// paramNames is used only by ErrorWrapNameAndArgs.
+func fallibleReturnNote(returnType string, mode ErrorWrapMode, paramNames []string) string {
+	if mode == ErrorWrapNone {
+		return ""
+	}
+	if returnType != "anyptr" && !strings.HasPrefix(returnType, "rawptr<") {
+		return ""
+	}
+	note := "may return NULL on failure"
+	if mode == ErrorWrapNameAndArgs && len(paramNames) > 0 {
+		note += fmt.Sprintf(" (%s)", strings.Join(paramNames, ", "))
+	}
+	return note
+}
+
+// docsPlugin emits a Markdown reference of the generated API: one
+// section per function, struct, enum, and constant.
+type docsPlugin struct{}
+
+func (docsPlugin) Name() string { return "docs" }
+
+func (docsPlugin) Generate(bg *BindingGenerator) ([]PluginFile, error) {
+	var sb strings.Builder
+	sb.WriteString("# Generated API Reference\n\n")
+
+	if len(bg.functions) > 0 {
+		sb.WriteString("## Functions\n\n")
+		names := make([]string, 0, len(bg.functions))
+		for name := range bg.functions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fn := bg.functions[name]
+			var params []string
+			for _, p := range fn.Parameters {
+				params = append(params, fmt.Sprintf("%s %s", p.Type, p.Name))
+			}
+			returnType := fn.ReturnType
+			if returnType == "" {
+				returnType = "void"
+			}
+			sb.WriteString(fmt.Sprintf("- `fn %s(%s):%s`", bg.renameSymbol(fn.CName), strings.Join(params, ", "), returnType))
+			paramNames := make([]string, 0, len(fn.Parameters))
+			for _, p := range fn.Parameters {
+				paramNames = append(paramNames, p.Name)
+			}
+			if note := fallibleReturnNote(returnType, bg.errorWrap, paramNames); note != "" {
+				sb.WriteString(fmt.Sprintf(" — %s", note))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(bg.structs) > 0 {
+		sb.WriteString("## Structs\n\n")
+		names := make([]string, 0, len(bg.structs))
+		for name := range bg.structs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			sb.WriteString(fmt.Sprintf("- `%s` (%d field(s))\n", name, len(bg.structs[name].Fields)))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(bg.enums) > 0 {
+		sb.WriteString("## Enums\n\n")
+		names := make([]string, 0, len(bg.enums))
+		for name := range bg.enums {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			sb.WriteString(fmt.Sprintf("- `%s`\n", name))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(bg.constants) > 0 {
+		sb.WriteString("## Constants\n\n")
+		names := make([]string, 0, len(bg.constants))
+		for name := range bg.constants {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			sb.WriteString(fmt.Sprintf("- `%s` = %s\n", name, bg.constants[name].Value))
+		}
+		sb.WriteString("\n")
+	}
+
+	return []PluginFile{{Name: "API.md", Content: sb.String()}}, nil
+}
+
+// stubsPlugin emits a Nature skeleton file with one commented-out call
+// stub per function, grouped by the function's prefix up to its first
+// underscore (e.g. "SDL_CreateWindow" groups under "SDL"), as a starting
+// point for hand-written smoke tests.
+type stubsPlugin struct{}
+
+func (stubsPlugin) Name() string { return "stubs" }
+
+func (stubsPlugin) Generate(bg *BindingGenerator) ([]PluginFile, error) {
+	if len(bg.functions) == 0 {
+		return nil, nil
+	}
+
+	groups := make(map[string][]string)
+	for name := range bg.functions {
+		group := name
+		if idx := strings.Index(name, "_"); idx > 0 {
+			group = name[:idx]
+		}
+		groups[group] = append(groups[group], name)
+	}
+
+	groupNames := make([]string, 0, len(groups))
+	for group := range groups {
+		groupNames = append(groupNames, group)
+	}
+	sort.Strings(groupNames)
+
+	var sb strings.Builder
+	sb.WriteString("// Generated skeleton stubs - fill in arguments and uncomment to use.\n\n")
+	for _, group := range groupNames {
+		fnNames := groups[group]
+		sort.Strings(fnNames)
+		sb.WriteString(fmt.Sprintf("// %s\n", group))
+		for _, fnName := range fnNames {
+			fn := bg.functions[fnName]
+			var argNames []string
+			for _, p := range fn.Parameters {
+				argNames = append(argNames, p.Name)
+			}
+			sb.WriteString(fmt.Sprintf("// %s(%s)\n", bg.renameSymbol(fn.CName), strings.Join(argNames, ", ")))
+		}
+		sb.WriteString("\n")
+	}
+
+	return []PluginFile{{Name: "stubs.n", Content: sb.String()}}, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookupPluginsResolvesBuiltins(t *testing.T) {
+	plugins, err := lookupPlugins("docs,stubs")
+	if err != nil {
+		t.Fatalf("lookupPlugins(\"docs,stubs\") error: %v", err)
+	}
+	if len(plugins) != 2 {
+		t.Fatalf("lookupPlugins(\"docs,stubs\") returned %d plugins, want 2", len(plugins))
+	}
+	if plugins[0].Name() != "docs" || plugins[1].Name() != "stubs" {
+		t.Fatalf("lookupPlugins(\"docs,stubs\") = %v, want [docs stubs]", []string{plugins[0].Name(), plugins[1].Name()})
+	}
+}
+
+func TestLookupPluginsSkipsBlankEntries(t *testing.T) {
+	plugins, err := lookupPlugins(" docs , , stubs ")
+	if err != nil {
+		t.Fatalf("lookupPlugins error: %v", err)
+	}
+	if len(plugins) != 2 {
+		t.Fatalf("lookupPlugins returned %d plugins, want 2 (blank entries skipped)", len(plugins))
+	}
+}
+
+func TestLookupPluginsUnknownName(t *testing.T) {
+	if _, err := lookupPlugins("docs,nonexistent"); err == nil {
+		t.Fatal("lookupPlugins(\"docs,nonexistent\") returned no error, want one naming the unknown plugin")
+	} else if !strings.Contains(err.Error(), "nonexistent") {
+		t.Fatalf("lookupPlugins error = %q, want it to name the unknown plugin", err.Error())
+	}
+}
+
+func TestRegisterPluginReplacesExisting(t *testing.T) {
+	name := "test-replace-plugin"
+	first := fakePlugin{name: name, files: []PluginFile{{Name: "first.txt"}}}
+	second := fakePlugin{name: name, files: []PluginFile{{Name: "second.txt"}}}
+
+	RegisterPlugin(first)
+	RegisterPlugin(second)
+	defer delete(pluginRegistry, name)
+
+	plugins, err := lookupPlugins(name)
+	if err != nil {
+		t.Fatalf("lookupPlugins(%q) error: %v", name, err)
+	}
+	files, err := plugins[0].Generate(nil)
+	if err != nil {
+		t.Fatalf("Generate error: %v", err)
+	}
+	if len(files) != 1 || files[0].Name != "second.txt" {
+		t.Fatalf("RegisterPlugin(%q) didn't replace the first registration: got %v", name, files)
+	}
+}
+
+type fakePlugin struct {
+	name  string
+	files []PluginFile
+}
+
+func (p fakePlugin) Name() string { return p.name }
+func (p fakePlugin) Generate(*BindingGenerator) ([]PluginFile, error) {
+	return p.files, nil
+}
+
+func TestDocsPluginGeneratesAPIReference(t *testing.T) {
+	bg := NewBindingGenerator()
+	bg.functions["sdl_init"] = FunctionBinding{
+		Name:       "sdl_init",
+		CName:      "sdl_init",
+		ReturnType: "rawptr<Window>",
+		Parameters: []Parameter{{Name: "flags", Type: "u32"}},
+	}
+	bg.structs["Window"] = StructBinding{Name: "Window", Fields: []StructField{{Name: "w"}, {Name: "h"}}}
+	bg.constants["MAX_WINDOWS"] = ConstantItem{Name: "MAX_WINDOWS", Value: "16"}
+
+	files, err := docsPlugin{}.Generate(bg)
+	if err != nil {
+		t.Fatalf("docsPlugin.Generate error: %v", err)
+	}
+	if len(files) != 1 || files[0].Name != "API.md" {
+		t.Fatalf("docsPlugin.Generate files = %v, want one API.md", files)
+	}
+
+	content := files[0].Content
+	if !strings.Contains(content, "## Functions") || !strings.Contains(content, "sdl_init") {
+		t.Errorf("API.md missing function section:\n%s", content)
+	}
+	if !strings.Contains(content, "may return NULL on failure") {
+		t.Errorf("API.md missing fallible-return note for a rawptr<> return:\n%s", content)
+	}
+	if !strings.Contains(content, "## Structs") || !strings.Contains(content, "Window") {
+		t.Errorf("API.md missing struct section:\n%s", content)
+	}
+	if !strings.Contains(content, "## Constants") || !strings.Contains(content, "MAX_WINDOWS") {
+		t.Errorf("API.md missing constants section:\n%s", content)
+	}
+}
+
+func TestDocsPluginErrorWrapNoneOmitsNote(t *testing.T) {
+	bg := NewBindingGenerator(WithErrorWrapping(ErrorWrapNone))
+	bg.functions["sdl_create"] = FunctionBinding{
+		Name: "sdl_create", CName: "sdl_create", ReturnType: "anyptr",
+	}
+
+	files, err := docsPlugin{}.Generate(bg)
+	if err != nil {
+		t.Fatalf("docsPlugin.Generate error: %v", err)
+	}
+	if strings.Contains(files[0].Content, "may return NULL") {
+		t.Errorf("ErrorWrapNone should omit the failure note:\n%s", files[0].Content)
+	}
+}
+
+func TestStubsPluginGroupsByPrefix(t *testing.T) {
+	bg := NewBindingGenerator()
+	bg.functions["SDL_Init"] = FunctionBinding{Name: "SDL_Init", CName: "SDL_Init"}
+	bg.functions["SDL_Quit"] = FunctionBinding{Name: "SDL_Quit", CName: "SDL_Quit"}
+	bg.functions["GL_Clear"] = FunctionBinding{Name: "GL_Clear", CName: "GL_Clear"}
+
+	files, err := stubsPlugin{}.Generate(bg)
+	if err != nil {
+		t.Fatalf("stubsPlugin.Generate error: %v", err)
+	}
+	if len(files) != 1 || files[0].Name != "stubs.n" {
+		t.Fatalf("stubsPlugin.Generate files = %v, want one stubs.n", files)
+	}
+
+	content := files[0].Content
+	if !strings.Contains(content, "// SDL") || !strings.Contains(content, "// GL") {
+		t.Errorf("stubs.n missing per-prefix group headers:\n%s", content)
+	}
+	if !strings.Contains(content, "SDL_Init") || !strings.Contains(content, "GL_Clear") {
+		t.Errorf("stubs.n missing generated stub calls:\n%s", content)
+	}
+}
+
+func TestStubsPluginEmptyWhenNoFunctions(t *testing.T) {
+	bg := NewBindingGenerator()
+	files, err := stubsPlugin{}.Generate(bg)
+	if err != nil {
+		t.Fatalf("stubsPlugin.Generate error: %v", err)
+	}
+	if files != nil {
+		t.Fatalf("stubsPlugin.Generate with no functions = %v, want nil", files)
+	}
+}
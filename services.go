@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ServiceConfig is the optional --services side-file mapping C function
+// name prefixes to Nature service names, e.g. {"prefixes": {"SDL_Render":
+// "Renderer"}} groups every SDL_Render* function under a "Renderer"
+// wrapper. Loading no file leaves bg.serviceConfig nil and servicesPlugin
+// generates nothing, so default behavior is unchanged.
+type ServiceConfig struct {
+	Prefixes map[string]string `json:"prefixes"`
+}
+
+// LoadServiceConfig reads a JSON --services file into bg.serviceConfig.
+func (bg *BindingGenerator) LoadServiceConfig(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading services file: %w", err)
+	}
+	var cfg ServiceConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("parsing services file %s: %w", path, err)
+	}
+	bg.serviceConfig = &cfg
+	return nil
+}
+
+// servicesPlugin groups functions by the configured prefixes into
+// wrapper functions under a service name. Only two of the idiomatic
+// naming heuristics are implemented here - prefix grouping and Is*/Has*
+// bool-return conversion - both renderable as the same single-expression
+// function bodies every other generated accessor in this tool uses. The
+// requested slice-from-ptr/len and thread-local-error-propagation
+// heuristics would need branching/indexing syntax this codebase has no
+// verified example of generating (the same constraint
+// writeAnonUnionAccessors documents for --union-mode=tagged), so they're
+// left out rather than guessed at.
+type servicesPlugin struct{}
+
+func (servicesPlugin) Name() string { return "services" }
+
+func (servicesPlugin) Generate(bg *BindingGenerator) ([]PluginFile, error) {
+	if bg.serviceConfig == nil || len(bg.serviceConfig.Prefixes) == 0 {
+		return nil, nil
+	}
+
+	funcNames := make([]string, 0, len(bg.functions))
+	for name := range bg.functions {
+		funcNames = append(funcNames, name)
+	}
+	sort.Strings(funcNames)
+
+	grouped := make(map[string][]string) // service name -> function names
+	for _, funcName := range funcNames {
+		prefix := longestMatchingPrefix(bg.serviceConfig.Prefixes, funcName)
+		if prefix == "" {
+			continue
+		}
+		service := bg.serviceConfig.Prefixes[prefix]
+		grouped[service] = append(grouped[service], funcName)
+	}
+	if len(grouped) == 0 {
+		return nil, nil
+	}
+
+	serviceNames := make([]string, 0, len(grouped))
+	for service := range grouped {
+		serviceNames = append(serviceNames, service)
+	}
+	sort.Strings(serviceNames)
+
+	var sb strings.Builder
+	sb.WriteString("// Generated service wrappers - grouped from --services prefixes.\n\n")
+	for _, service := range serviceNames {
+		for _, funcName := range grouped[service] {
+			fn := bg.functions[funcName]
+			prefix := longestMatchingPrefix(bg.serviceConfig.Prefixes, funcName)
+			suffix := strings.TrimPrefix(funcName, prefix)
+
+			var paramDecls, argNames []string
+			for _, p := range fn.Parameters {
+				paramDecls = append(paramDecls, fmt.Sprintf("%s %s", p.Type, p.Name))
+				argNames = append(argNames, p.Name)
+			}
+
+			returnType := fn.ReturnType
+			if returnType == "" {
+				returnType = "void"
+			}
+
+			isBoolConversion := (strings.HasPrefix(suffix, "Is") || strings.HasPrefix(suffix, "Has")) && returnType == "i32"
+			if isBoolConversion {
+				returnType = "bool"
+			}
+
+			sb.WriteString(fmt.Sprintf("fn %s_%s(%s):%s {\n", service, suffix, strings.Join(paramDecls, ", "), returnType))
+			call := fmt.Sprintf("%s(%s)", bg.renameSymbol(fn.CName), strings.Join(argNames, ", "))
+			if isBoolConversion {
+				call += " != 0"
+			}
+			sb.WriteString(fmt.Sprintf("    return %s\n", call))
+			sb.WriteString("}\n\n")
+		}
+	}
+
+	return []PluginFile{{Name: "services.n", Content: sb.String()}}, nil
+}
+
+// longestMatchingPrefix returns the configured prefix that funcName
+// starts with, preferring the longest match so "SDL_RenderDraw" prefers
+// a more specific "SDL_RenderDraw" entry over a broader "SDL_Render" one
+// if both are configured.
+func longestMatchingPrefix(prefixes map[string]string, funcName string) string {
+	best := ""
+	for prefix := range prefixes {
+		if strings.HasPrefix(funcName, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	return best
+}
+
+func init() {
+	RegisterPlugin(servicesPlugin{})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// This file implements --split (WithSplitOutput): for a large umbrella
+// header such as Raylib's, a single multi-thousand-line bindings.n file
+// stresses editors just as badly as it would in any other generated
+// language, so --split treats outputFile as a directory and partitions
+// the same content generateNatureBindings would emit into one file per
+// declaration category instead.
+
+// writeSplit is WriteAll's --split path: it renders bg's parsed bindings
+// into common.n, constants.n, enums.n, structs.n, and functions.n under
+// dir (created if missing), rather than a single file. Each file is
+// self-contained Nature source covering one generateNatureBindings
+// section, so together they cover exactly the same declarations the
+// non-split output would, just split by category instead of concatenated.
+func (bg *BindingGenerator) writeSplit(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating split output directory %s: %w", dir, err)
+	}
+
+	isReachable := bg.reachabilityFilter()
+
+	var common strings.Builder
+	common.WriteString("// Generated Nature bindings (common.n)\n")
+	common.WriteString("// This file was automatically generated by naturebindgen\n\n")
+	bg.writeLinkDirectives(&common)
+	bg.writeTypeDefsSection(&common, isReachable)
+
+	var constants strings.Builder
+	bg.writeConstantsSection(&constants)
+
+	var enums strings.Builder
+	bg.writeEnumConstantsSection(&enums)
+
+	var structs strings.Builder
+	bg.writeUnionStructSection(&structs, isReachable)
+
+	var functions strings.Builder
+	bg.writeFunctionsSection(&functions)
+
+	files := map[string]string{
+		"common.n":    common.String(),
+		"constants.n": constants.String(),
+		"enums.n":     enums.String(),
+		"structs.n":   structs.String(),
+		"functions.n": functions.String(),
+	}
+
+	for name, content := range files {
+		if !bg.noFormat {
+			content = formatNatureSource(content)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+	}
+	return nil
+}